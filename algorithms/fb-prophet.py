@@ -77,14 +77,14 @@ if __name__ == "__main__":
             y_axis.append(usage)
             return_objects.append({
                 "label": key,
-                "x": year,
-                "y": usage,
+                "x": int(year),
+                "y": float(usage),
                 "uncertainty": [0, 0]
             })
         ds = pandas.to_datetime(x_axis, format="%Y")
         df['ds'] = ds
         m.fit(df)
-        meta["realDataUntil"][str(key)] = x_axis[-1]
+        meta["realDataUntil"][str(key)] = int(x_axis[-1])
         future = m.make_future_dataframe(periods=parameters["size"], freq="Y")
         forecast = m.predict(future)
         forecast = forecast[['ds', 'yhat', 'yhat_lower', 'yhat_upper']].copy()
@@ -94,13 +94,14 @@ if __name__ == "__main__":
             if len(predicted_references) < len(y_axis):
                 predicted_references.append(row['yhat'])
             else:
-                if idx == len(y_axis):
+                # the first future date falls into the last year with real data
+                if row['ds'].year <= x_axis[-1]:
                     continue
                 return_objects.append({
                     "label": key,
-                    "x": row['ds'].year,
-                    "y": row['yhat'],
-                    "uncertainty": [row['yhat_lower'], row['yhat_upper']]
+                    "x": int(row['ds'].year),
+                    "y": float(row['yhat']),
+                    "uncertainty": [float(row['yhat_lower']), float(row['yhat_upper'])]
                 })
 
         r_square = sklearn.metrics.r2_score(y_axis, predicted_references)
@@ -57,8 +57,8 @@ if __name__ == "__main__":
             y_axis.append(usage)
             return_objects.append({
                 "label": key,
-                "x": year,
-                "y": usage
+                "x": int(year),
+                "y": float(usage)
             })
 
         prediction_x_axis = numpy.linspace(start=x_axis[0], stop=x_axis[-1] + parameters["size"], num=len(y_axis) + parameters["size"], dtype=int)
@@ -72,10 +72,12 @@ if __name__ == "__main__":
         meta["rScores"][key] = r_square
         meta["realDataUntil"][key] = int(x_axis[-1])
         for year in prediction_x_axis:
+            if year <= x_axis[-1]:
+                continue
             idx = int(year) - int(prediction_x_axis[0])
             return_objects.append({
                 "label": key,
-                "x": f"{int(year)}",
+                "x": int(year),
                 "y": float(prediction_y_axis[idx])
             })
 
@@ -0,0 +1,113 @@
+// Package auth enforces the authorization configuration of the service. The
+// tokens are issued by the api gateway and contain the groups and scopes of
+// the user
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// Scope describes a group of routes which require the same permission
+type Scope string
+
+const (
+	// ScopeRead allows listing the algorithms and reading the run history
+	ScopeRead Scope = "read"
+
+	// ScopeRun allows executing forecasts and managing forecast jobs
+	ScopeRun Scope = "run"
+
+	// ScopeManage allows uploading, replacing and removing algorithms and
+	// executing on-demand forecasts with scripts supplied in the request
+	ScopeManage Scope = "manage"
+)
+
+// Config contains the authorization configuration read from the file
+// configured by AUTH_CONFIG_FILE_LOCATION
+type Config struct {
+	// EnableAuth enables the authorization of the read and run scopes. The
+	// manage scope is always authorized
+	EnableAuth bool `json:"enableAuth"`
+
+	// RequireUserID rejects tokens which do not identify the user in the
+	// subject claim
+	RequireUserID bool `json:"requireUserID"`
+
+	// RequiredUserGroup contains the group a user needs to be member of to
+	// access the service. Staff members may access the service regardless of
+	// their groups
+	RequiredUserGroup string `json:"requiredUserGroup"`
+
+	// Scopes maps the scopes of the service to the values required in the
+	// scope claim of the token. Scopes mapped to an empty value only require
+	// the user group
+	Scopes map[Scope]string `json:"scopes"`
+
+	// VerificationKeyFile contains the path to a file with the PEM encoded
+	// public keys used to verify the signature of the tokens. If no file is
+	// configured, the signature is not verified since the tokens are expected
+	// to be checked by the api gateway. The file is required unless the
+	// management of algorithms is disabled
+	VerificationKeyFile string `json:"verificationKeyFile"`
+
+	// DisableManagement removes the routes of the manage scope from the
+	// service
+	DisableManagement bool `json:"disableManagement"`
+
+	// keys contains the keys read from the verification key file
+	keys jwk.Set
+}
+
+// LoadConfig reads the authorization configuration from the file and loads the
+// verification keys if they are configured. The verification keys are required
+// unless the management of algorithms is disabled
+func LoadConfig(path string) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("unable to open authorization configuration: %w", err)
+	}
+	defer file.Close()
+
+	var config Config
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&config)
+	if err != nil {
+		return Config{}, fmt.Errorf("unable to parse authorization configuration: %w", err)
+	}
+
+	for scope := range config.Scopes {
+		switch scope {
+		case ScopeRead, ScopeRun, ScopeManage:
+		default:
+			return Config{}, fmt.Errorf("unknown scope '%s'", scope)
+		}
+	}
+	// the management of algorithms is always authorized, therefore the group
+	// is required even if the authorization is disabled
+	if strings.TrimSpace(config.RequiredUserGroup) == "" {
+		return Config{}, errors.New("no required user group set")
+	}
+	// unverified tokens could be forged to claim the manage scope or the
+	// staff membership, which would allow anyone to execute arbitrary scripts
+	if !config.DisableManagement && config.VerificationKeyFile == "" {
+		return Config{}, errors.New("verification keys are required unless the management of algorithms is disabled")
+	}
+
+	if config.VerificationKeyFile != "" {
+		config.keys, err = jwk.ReadFile(config.VerificationKeyFile, jwk.WithPEM(true))
+		if err != nil {
+			return Config{}, fmt.Errorf("unable to read verification keys: %w", err)
+		}
+		if config.keys.Len() == 0 {
+			return Config{}, errors.New("verification key file does not contain any key")
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	wisdomMiddleware "github.com/wisdom-oss/microservice-middlewares/v4"
+
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+)
+
+// issuer contains the issuer of the tokens accepted by the service
+const issuer = "api-gateway"
+
+// ErrMissingAuthorizationHeader is an error that occurs when the request did not
+// contain the `Authorization` header.
+var ErrMissingAuthorizationHeader = problems.New("MISSING_AUTHORIZATION_HEADER")
+
+// ErrUnsupportedTokenScheme is an error that occurs when the request did not use
+// the Bearer token scheme.
+var ErrUnsupportedTokenScheme = problems.New("UNSUPPORTED_TOKEN_SCHEME")
+
+// ErrJWTMalformed is an error that occurs when the token could not be parsed.
+var ErrJWTMalformed = problems.New("JWT_MALFORMED")
+
+// ErrJWTExpired is an error that occurs when the token has already expired.
+var ErrJWTExpired = problems.New("JWT_EXPIRED")
+
+// ErrJWTNotYetValid is an error that occurs when the token is used before the
+// time it becomes valid.
+var ErrJWTNotYetValid = problems.New("JWT_NOT_YET_VALID")
+
+// ErrJWTNotCreatedYet is an error that occurs when the token has been issued in
+// the future.
+var ErrJWTNotCreatedYet = problems.New("JWT_NOT_CREATED_YET")
+
+// ErrJWTInvalidIssuer is an error that occurs when the token has not been issued
+// by the api gateway.
+var ErrJWTInvalidIssuer = problems.New("JWT_INVALID_ISSUER")
+
+// ErrJWTNoGroups is an error that occurs when the token does not contain the
+// groups of the user.
+var ErrJWTNoGroups = problems.New("JWT_NO_GROUPS")
+
+// ErrForbidden is an error that occurs when the user is not a member of the
+// required group.
+var ErrForbidden = problems.New("FORBIDDEN")
+
+// ErrMissingUserID is an error that occurs when the token does not identify
+// the user although the configuration requires it.
+var ErrMissingUserID = problems.New("MISSING_USER_ID")
+
+// ErrJWTInvalidSignature is an error that occurs when the signature of the
+// token could not be verified using the configured keys.
+var ErrJWTInvalidSignature = problems.New("JWT_INVALID_SIGNATURE")
+
+// ErrMissingScope is an error that occurs when the user is a member of the
+// required group but the token does not grant the scope of the route.
+var ErrMissingScope = problems.New("MISSING_SCOPE")
+
+// Middleware returns a middleware which only passes requests with a token
+// granting the scope to the next handler.
+// Requests for the read and run scopes are passed on unchecked if the
+// authorization is disabled in the configuration. The manage scope is always
+// checked.
+// The middleware requires the error handler of the wisdom middlewares.
+func Middleware(config Config, scope Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !config.EnableAuth && scope != ScopeManage {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// access the error handlers
+			errorHandler := r.Context().Value(wisdomMiddleware.ErrorChannelName).(chan<- interface{})
+			statusChannel := r.Context().Value(wisdomMiddleware.StatusChannelName).(<-chan bool)
+
+			ctx, err := authorize(r, config, scope)
+			if err != nil {
+				errorHandler <- *err
+				<-statusChannel
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authorize parses the token of the request and checks if it grants the
+// scope. The returned context contains the same values as the context set by
+// the authorization middleware of the wisdom middlewares
+func authorize(r *http.Request, config Config, scope Scope) (context.Context, *problems.Error) {
+	ctx := r.Context()
+
+	authHeader := strings.TrimSpace(r.Header.Get("Authorization"))
+	if authHeader == "" {
+		return nil, &ErrMissingAuthorizationHeader
+	}
+	rawToken, isBearer := strings.CutPrefix(authHeader, "Bearer ")
+	if !isBearer {
+		return nil, &ErrUnsupportedTokenScheme
+	}
+
+	options := []jwt.ParseOption{jwt.WithValidate(true), jwt.WithIssuer(issuer)}
+	if config.keys != nil {
+		options = append(options, jwt.WithKeySet(config.keys, jws.WithInferAlgorithmFromKey(true), jws.WithRequireKid(false)))
+	} else {
+		options = append(options, jwt.WithVerify(false))
+	}
+	token, err := jwt.ParseString(strings.TrimSpace(rawToken), options...)
+	if err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired()):
+			return nil, &ErrJWTExpired
+		case errors.Is(err, jwt.ErrTokenNotYetValid()):
+			return nil, &ErrJWTNotYetValid
+		case errors.Is(err, jwt.ErrInvalidIssuedAt()):
+			return nil, &ErrJWTNotCreatedYet
+		case errors.Is(err, jwt.ErrInvalidIssuer()):
+			return nil, &ErrJWTInvalidIssuer
+		case jws.IsVerificationError(err):
+			e := ErrJWTInvalidSignature
+			e.Error = err.Error()
+			return nil, &e
+		default:
+			e := ErrJWTMalformed
+			e.Error = err.Error()
+			return nil, &e
+		}
+	}
+
+	if config.RequireUserID && strings.TrimSpace(token.Subject()) == "" {
+		return nil, &ErrMissingUserID
+	}
+
+	// staff members may access every resource regardless of their groups
+	// and scopes
+	if isStaff(token) {
+		return context.WithValue(ctx, "auth.admin", true), nil
+	}
+
+	rawGroups, groupsSet := token.PrivateClaims()["groups"].([]interface{})
+	if !groupsSet {
+		return nil, &ErrJWTNoGroups
+	}
+	isMember := slices.ContainsFunc(rawGroups, func(group interface{}) bool {
+		name, _ := group.(string)
+		return name == config.RequiredUserGroup
+	})
+	if !isMember {
+		return nil, &ErrForbidden
+	}
+	ctx = context.WithValue(ctx, "auth.group", config.RequiredUserGroup)
+
+	requiredScope := config.Scopes[scope]
+	if requiredScope == "" {
+		return ctx, nil
+	}
+	rawScopes, _ := token.PrivateClaims()["scope"].(string)
+	if !slices.Contains(strings.Fields(rawScopes), requiredScope) {
+		return nil, &ErrMissingScope
+	}
+	return ctx, nil
+}
+
+// isStaff reports if the token marks the user as staff member. The claim is
+// either set as boolean or as string
+func isStaff(token jwt.Token) bool {
+	switch staff := token.PrivateClaims()["staff"].(type) {
+	case bool:
+		return staff
+	case string:
+		return staff == "true"
+	}
+	return false
+}
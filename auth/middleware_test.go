@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// testGroup contains the group required by the configuration of the tests
+const testGroup = "usage-forecasts"
+
+// testScopes contains the scope claims required by the configuration of the
+// tests
+var testScopes = map[Scope]string{
+	ScopeRead:   "usage-forecasts:read",
+	ScopeRun:    "usage-forecasts:run",
+	ScopeManage: "usage-forecasts:manage",
+}
+
+// newTestKey generates the key used to sign the tokens of a test
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	return key
+}
+
+// writeTestConfig writes the configuration into a temporary directory and
+// returns its path. If the key is set, its public key is written into the
+// verification key file
+func writeTestConfig(t *testing.T, config Config, key *rsa.PrivateKey) string {
+	t.Helper()
+	directory := t.TempDir()
+	if key != nil {
+		publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatalf("unable to encode public key: %v", err)
+		}
+		config.VerificationKeyFile = filepath.Join(directory, "keys.pem")
+		encoded := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+		if err := os.WriteFile(config.VerificationKeyFile, encoded, 0o600); err != nil {
+			t.Fatalf("unable to write verification keys: %v", err)
+		}
+	}
+	raw, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("unable to encode configuration: %v", err)
+	}
+	path := filepath.Join(directory, "authConfig.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatalf("unable to write configuration: %v", err)
+	}
+	return path
+}
+
+// loadTestConfig loads a configuration enforcing every scope and verifying
+// the tokens using the key
+func loadTestConfig(t *testing.T, key *rsa.PrivateKey) Config {
+	t.Helper()
+	path := writeTestConfig(t, Config{
+		EnableAuth:        true,
+		RequireUserID:     true,
+		RequiredUserGroup: testGroup,
+		Scopes:            testScopes,
+	}, key)
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unable to load configuration: %v", err)
+	}
+	return config
+}
+
+// signTestToken creates a token which is valid for the configuration of the
+// tests after applying the changes and signs it with the key
+func signTestToken(t *testing.T, key *rsa.PrivateKey, change func(token jwt.Token)) string {
+	t.Helper()
+	token, err := jwt.NewBuilder().
+		Issuer(issuer).
+		Subject("user-1").
+		IssuedAt(time.Now().Add(-time.Minute)).
+		Expiration(time.Now().Add(time.Hour)).
+		Claim("groups", []string{"other", testGroup}).
+		Claim("scope", "usage-forecasts:read usage-forecasts:run usage-forecasts:manage").
+		Build()
+	if err != nil {
+		t.Fatalf("unable to build token: %v", err)
+	}
+	if change != nil {
+		change(token)
+	}
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, key))
+	if err != nil {
+		t.Fatalf("unable to sign token: %v", err)
+	}
+	return string(signed)
+}
+
+// authorizeTestToken authorizes the token for the scope and returns the code
+// of the error. The code is empty if the token has been accepted
+func authorizeTestToken(config Config, scope Scope, token string) string {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	_, err := authorize(r, config, scope)
+	if err != nil {
+		return err.Code
+	}
+	return ""
+}
+
+func TestAuthorizeScopes(t *testing.T) {
+	key := newTestKey(t)
+	config := loadTestConfig(t, key)
+
+	for scope, claim := range testScopes {
+		t.Run(string(scope), func(t *testing.T) {
+			granted := signTestToken(t, key, func(token jwt.Token) {
+				_ = token.Set("scope", "openid "+claim)
+			})
+			if code := authorizeTestToken(config, scope, granted); code != "" {
+				t.Errorf("token granting the scope rejected with %s", code)
+			}
+
+			// the token grants every scope except the tested one
+			var others string
+			for otherScope, otherClaim := range testScopes {
+				if otherScope != scope {
+					others += otherClaim + " "
+				}
+			}
+			missing := signTestToken(t, key, func(token jwt.Token) {
+				_ = token.Set("scope", others)
+			})
+			if code := authorizeTestToken(config, scope, missing); code != ErrMissingScope.Code {
+				t.Errorf("expected %s for token without the scope, got '%s'", ErrMissingScope.Code, code)
+			}
+		})
+	}
+}
+
+func TestAuthorizeRejectsInvalidTokens(t *testing.T) {
+	key := newTestKey(t)
+	config := loadTestConfig(t, key)
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{
+			name:  "bad signature",
+			token: signTestToken(t, newTestKey(t), nil),
+			want:  ErrJWTInvalidSignature.Code,
+		},
+		{
+			name: "wrong issuer",
+			token: signTestToken(t, key, func(token jwt.Token) {
+				_ = token.Set(jwt.IssuerKey, "somebody-else")
+			}),
+			want: ErrJWTInvalidIssuer.Code,
+		},
+		{
+			name: "missing group claim",
+			token: signTestToken(t, key, func(token jwt.Token) {
+				_ = token.Remove("groups")
+			}),
+			want: ErrJWTNoGroups.Code,
+		},
+		{
+			name: "not a member of the required group",
+			token: signTestToken(t, key, func(token jwt.Token) {
+				_ = token.Set("groups", []string{"other"})
+			}),
+			want: ErrForbidden.Code,
+		},
+		{
+			name: "missing user id",
+			token: signTestToken(t, key, func(token jwt.Token) {
+				_ = token.Remove(jwt.SubjectKey)
+			}),
+			want: ErrMissingUserID.Code,
+		},
+		{
+			name: "forged staff member",
+			token: signTestToken(t, newTestKey(t), func(token jwt.Token) {
+				_ = token.Set("staff", true)
+			}),
+			want: ErrJWTInvalidSignature.Code,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for scope := range testScopes {
+				if code := authorizeTestToken(config, scope, test.token); code != test.want {
+					t.Errorf("expected %s for scope %s, got '%s'", test.want, scope, code)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfigRequiresVerificationKeys(t *testing.T) {
+	config := Config{RequiredUserGroup: testGroup, Scopes: testScopes}
+	if _, err := LoadConfig(writeTestConfig(t, config, nil)); err == nil {
+		t.Error("configuration without verification keys accepted while the management is enabled")
+	}
+
+	config.DisableManagement = true
+	if _, err := LoadConfig(writeTestConfig(t, config, nil)); err != nil {
+		t.Errorf("configuration without verification keys rejected while the management is disabled: %v", err)
+	}
+}
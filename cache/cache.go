@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// Backend describes a storage for forecast results. Implementations need to
+// be safe for concurrent use
+type Backend interface {
+	// Get returns the value stored for the key. If the key is unknown or the
+	// value expired, false is returned
+	Get(key string) ([]byte, bool)
+
+	// Set stores the value for the key and replaces existing values
+	Set(key string, value []byte)
+}
+
+// Key calculates a cache key from the supplied parts. The length of every part
+// is included in the key to prevent different splits of the same bytes from
+// resulting in the same key
+func Key(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		_ = binary.Write(hash, binary.BigEndian, uint64(len(part)))
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
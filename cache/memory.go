@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Memory is an in-memory backend keeping a limited number of entries for a
+// limited time. If the backend is full, the least recently used entry is
+// removed
+type Memory struct {
+	// lock protects the entries and the usage order
+	lock sync.Mutex
+
+	// ttl contains the duration for which an entry is valid
+	ttl time.Duration
+
+	// size contains the maximal number of entries
+	size int
+
+	// entries contains the elements of the usage order indexed by their key
+	entries map[string]*list.Element
+
+	// usage contains the entries ordered by their last usage, starting with
+	// the most recently used entry
+	usage *list.List
+}
+
+// entry contains a single value stored in the memory backend
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemory creates a new in-memory backend keeping at most size entries for
+// the duration of the ttl
+func NewMemory(ttl time.Duration, size int) *Memory {
+	return &Memory{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]*list.Element),
+		usage:   list.New(),
+	}
+}
+
+// Get returns the value stored for the key if it did not expire yet
+func (m *Memory) Get(key string) ([]byte, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	element, found := m.entries[key]
+	if !found {
+		return nil, false
+	}
+	e := element.Value.(*entry)
+	if time.Now().After(e.expiresAt) {
+		m.usage.Remove(element)
+		delete(m.entries, key)
+		return nil, false
+	}
+	m.usage.MoveToFront(element)
+	return e.value, true
+}
+
+// Set stores the value for the key and removes the least recently used
+// entries if the backend is full
+func (m *Memory) Set(key string, value []byte) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if element, found := m.entries[key]; found {
+		m.usage.Remove(element)
+		delete(m.entries, key)
+	}
+	m.entries[key] = m.usage.PushFront(&entry{
+		key:       key,
+		value:     value,
+		expiresAt: time.Now().Add(m.ttl),
+	})
+	for m.usage.Len() > m.size {
+		oldest := m.usage.Back()
+		m.usage.Remove(oldest)
+		delete(m.entries, oldest.Value.(*entry).key)
+	}
+}
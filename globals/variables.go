@@ -1,7 +1,16 @@
 package globals
 
 import (
+	"time"
+
 	"github.com/qustavo/dotsql"
+
+	"github.com/wisdom-oss/service-usage-forecasts/auth"
+	"github.com/wisdom-oss/service-usage-forecasts/cache"
+	"github.com/wisdom-oss/service-usage-forecasts/jobs"
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+	"github.com/wisdom-oss/service-usage-forecasts/registry"
+	"github.com/wisdom-oss/service-usage-forecasts/scheduler"
 )
 
 // This file contains globally shared variables (e.g., service name, sql queries)
@@ -16,3 +25,35 @@ var SqlQueries *dotsql.DotSql
 // they were set to. However, this variable only contains the configured environment
 // variables
 var Environment map[string]string = make(map[string]string)
+
+// Algorithms contains the registry of algorithms which has been loaded during
+// the startup of the service
+var Algorithms *registry.Registry
+
+// AlgorithmTimeout contains the maximal duration of an algorithm execution if
+// the algorithm does not specify its own timeout
+var AlgorithmTimeout time.Duration
+
+// OnDemandTimeout contains the maximal duration of the execution of a script
+// supplied for an on-demand forecast
+var OnDemandTimeout time.Duration
+
+// DebugAlgorithms enables sending an excerpt of the error output of failed
+// algorithms to the clients
+var DebugAlgorithms bool
+
+// Scheduler limits the number of algorithm executions running at the same time
+var Scheduler *scheduler.Scheduler
+
+// Jobs contains the asynchronous forecasts submitted to the service
+var Jobs *jobs.Store
+
+// Cache contains the backend used to cache the outputs of the algorithms. If
+// caching is disabled, the cache is nil
+var Cache cache.Backend
+
+// AuthConfig contains the authorization configuration enforced on the routes
+var AuthConfig auth.Config
+
+// Errors contains the catalogue of errors returned by the service
+var Errors *problems.Catalogue
@@ -8,6 +8,10 @@ import (
 	"github.com/wisdom-oss/service-usage-forecasts/types"
 )
 
+// interpreter contains the name of the interpreter executing the scripts of
+// the algorithms. It is looked up in the search path of the service
+const interpreter = "python"
+
 // GetAlgorithmMetadata reads the yaml metadata file supplied by the filepath
 func GetAlgorithmMetadata(metadataFilePath string) (types.AlgorithmMetadata, error) {
 	var metadata types.AlgorithmMetadata
@@ -15,6 +19,7 @@ func GetAlgorithmMetadata(metadataFilePath string) (types.AlgorithmMetadata, err
 	if err != nil {
 		return types.AlgorithmMetadata{}, err
 	}
+	defer file.Close()
 	err = yaml.NewDecoder(file).Decode(&metadata)
 	if err != nil {
 		return types.AlgorithmMetadata{}, err
@@ -1,13 +1,33 @@
 package helpers
 
-import "os/exec"
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
 
 // CallAlgorithm calls the algorithm with the needed arguments.
-func CallAlgorithm(algorithmPath, dataFile, outputFile, parameterFile string) error {
-	cmd := exec.Command(algorithmPath, dataFile, outputFile, parameterFile)
+// The algorithm is started in a new process group which is killed completely
+// as soon as the context is cancelled. In this case, the error of the context
+// is returned.
+// The output streams of the algorithm are captured. If the algorithm exits
+// unsuccessfully, an ExecutionError containing them is returned.
+// The sandbox is only supported on Linux and therefore ignored.
+func CallAlgorithm(ctx context.Context, _ *Sandbox, algorithmPath, dataFile, outputFile, parameterFile string) error {
+	cmd := exec.CommandContext(ctx, interpreter, algorithmPath, dataFile, outputFile, parameterFile)
+	var stdout, stderr outputBuffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
 	err := cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
-		return err
+		return executionError(err, &stdout, &stderr)
 	}
 	return nil
 }
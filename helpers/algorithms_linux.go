@@ -1,13 +1,55 @@
 package helpers
 
-import "os/exec"
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
 
 // CallAlgorithm calls the algorithm with the needed arguments.
-func CallAlgorithm(algorithmPath, dataFile, outputFile, parameterFile string) error {
-	cmd := exec.Command("python", algorithmPath, dataFile, outputFile, parameterFile)
-	err := cmd.Run()
+// The algorithm is started in a new process group which is killed completely
+// as soon as the context is cancelled. In this case, the error of the context
+// is returned.
+// The output streams of the algorithm are captured. If the algorithm exits
+// unsuccessfully, an ExecutionError containing them is returned.
+// If a sandbox is supplied, the algorithm is executed within the sandbox. The
+// files passed to the algorithm need to be located in the same directory,
+// which is used as the private working directory of the algorithm. If the
+// sandbox could not be set up, an ErrSandbox is returned.
+func CallAlgorithm(ctx context.Context, sandbox *Sandbox, algorithmPath, dataFile, outputFile, parameterFile string) error {
+	var cmd *exec.Cmd
+	if sandbox != nil {
+		var err error
+		cmd, err = sandboxedCommand(ctx, sandbox, algorithmPath, dataFile, outputFile, parameterFile)
+		if err != nil {
+			return err
+		}
+		cmd.Dir = filepath.Dir(dataFile)
+	} else {
+		cmd = exec.CommandContext(ctx, interpreter, algorithmPath, dataFile, outputFile, parameterFile)
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+	var stdout, stderr outputBuffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	err := cmd.Start()
+	if err != nil && sandbox != nil {
+		// creating the namespaces requires the service to run as root
+		return fmt.Errorf("%w: %w", ErrSandbox, err)
+	}
+	if err == nil {
+		err = cmd.Wait()
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
-		return err
+		return sandboxError(executionError(err, &stdout, &stderr))
 	}
 	return nil
 }
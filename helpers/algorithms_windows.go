@@ -1,21 +1,27 @@
 package helpers
 
 import (
-	"bytes"
-	"fmt"
-	"os"
+	"context"
 	"os/exec"
 )
 
 // CallAlgorithm calls the algorithm with the needed arguments.
-func CallAlgorithm(algorithmPath, dataFile, outputFile, parameterFile string) error {
-	var stderr bytes.Buffer
-	cmd := exec.Command("python", algorithmPath, dataFile, outputFile, parameterFile)
-	cmd.Stdout = os.Stdout
+// The algorithm is killed as soon as the context is cancelled. In this case,
+// the error of the context is returned.
+// The output streams of the algorithm are captured. If the algorithm exits
+// unsuccessfully, an ExecutionError containing them is returned.
+// The sandbox is only supported on Linux and therefore ignored.
+func CallAlgorithm(ctx context.Context, _ *Sandbox, algorithmPath, dataFile, outputFile, parameterFile string) error {
+	var stdout, stderr outputBuffer
+	cmd := exec.CommandContext(ctx, interpreter, algorithmPath, dataFile, outputFile, parameterFile)
+	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	err := cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
-		return fmt.Errorf("error while executing algorithm: %s", stderr.String())
+		return executionError(err, &stdout, &stderr)
 	}
 	return nil
 }
@@ -0,0 +1,140 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"syscall"
+	"unicode"
+)
+
+// maxCapturedOutput limits the number of bytes kept from each output stream
+// of an algorithm. Since tracebacks end with the actual error, the end of the
+// streams is kept
+const maxCapturedOutput = 64 * 1024
+
+// maxExcerptLength limits the number of bytes in the excerpt of the error
+// output which is sent to clients
+const maxExcerptLength = 2048
+
+// ExecutionError is returned if an algorithm exited unsuccessfully. It
+// contains the exit code, the signal which terminated the algorithm and the
+// captured output streams
+type ExecutionError struct {
+	// ExitCode contains the exit code of the algorithm. It is -1 if the
+	// algorithm has been terminated by a signal
+	ExitCode int
+
+	// Signal contains the name of the signal which terminated the algorithm.
+	// It is empty if the algorithm exited by itself
+	Signal string
+
+	// Stdout contains the end of the standard output of the algorithm
+	Stdout string
+
+	// Stderr contains the end of the error output of the algorithm
+	Stderr string
+
+	// Truncated indicates that the beginning of at least one output stream
+	// has been discarded
+	Truncated bool
+
+	// err contains the error returned by the execution
+	err error
+}
+
+func (e *ExecutionError) Error() string {
+	if e.Signal != "" {
+		return fmt.Sprintf("algorithm terminated by signal '%s'", e.Signal)
+	}
+	return fmt.Sprintf("algorithm exited with code %d", e.ExitCode)
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.err
+}
+
+// absolutePath matches absolute paths in the output of an algorithm to
+// remove the directories from excerpts
+var absolutePath = regexp.MustCompile(`(?:/[^/\s"':]+)+/([^/\s"':]+)`)
+
+// escapeSequence matches the escape sequences used by terminals, e.g., to
+// color the output
+var escapeSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// Excerpt returns the end of the error output which is safe to be sent to
+// clients. Directories are removed from absolute paths, leaving only the name
+// of the file, and escape sequences and control characters are removed
+func (e *ExecutionError) Excerpt() string {
+	excerpt := escapeSequence.ReplaceAllString(e.Stderr, "")
+	excerpt = absolutePath.ReplaceAllString(excerpt, "$1")
+	excerpt = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, excerpt)
+	excerpt = strings.ToValidUTF8(excerpt, "")
+	if len(excerpt) > maxExcerptLength {
+		excerpt = excerpt[len(excerpt)-maxExcerptLength:]
+		// drop the partial line at the beginning of the excerpt unless it is
+		// the only line
+		index := strings.IndexByte(excerpt, '\n')
+		if index >= 0 && strings.TrimSpace(excerpt[index+1:]) != "" {
+			excerpt = excerpt[index+1:]
+		}
+		excerpt = strings.ToValidUTF8(excerpt, "")
+	}
+	return strings.TrimSpace(excerpt)
+}
+
+// outputBuffer keeps the last maxCapturedOutput bytes written to it
+type outputBuffer struct {
+	data      []byte
+	truncated bool
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	// the buffer is only shortened once it grew to twice the limit to avoid
+	// copying the data on every write
+	if len(b.data) > 2*maxCapturedOutput {
+		b.data = append(b.data[:0], b.data[len(b.data)-maxCapturedOutput:]...)
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *outputBuffer) String() string {
+	if len(b.data) > maxCapturedOutput {
+		return string(b.data[len(b.data)-maxCapturedOutput:])
+	}
+	return string(b.data)
+}
+
+func (b *outputBuffer) Truncated() bool {
+	return b.truncated || len(b.data) > maxCapturedOutput
+}
+
+// executionError wraps the error of an algorithm which exited unsuccessfully
+// into an ExecutionError. Errors which occurred while starting the algorithm
+// are returned unchanged
+func executionError(err error, stdout, stderr *outputBuffer) error {
+	var exitError *exec.ExitError
+	if !errors.As(err, &exitError) {
+		return err
+	}
+	executionError := &ExecutionError{
+		ExitCode:  exitError.ExitCode(),
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.Truncated() || stderr.Truncated(),
+		err:       err,
+	}
+	if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		executionError.Signal = status.Signal().String()
+	}
+	return executionError
+}
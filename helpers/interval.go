@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// intervalUnits maps the units accepted in intervals to their length. Units
+// with a length in months are not converted into microseconds since the
+// length of a month varies
+var intervalUnits = map[string]struct {
+	months       int64
+	microseconds int64
+}{
+	"second":  {microseconds: int64(time.Second / time.Microsecond)},
+	"minute":  {microseconds: int64(time.Minute / time.Microsecond)},
+	"hour":    {microseconds: int64(time.Hour / time.Microsecond)},
+	"day":     {microseconds: int64(24 * time.Hour / time.Microsecond)},
+	"week":    {microseconds: int64(7 * 24 * time.Hour / time.Microsecond)},
+	"month":   {months: 1},
+	"quarter": {months: 3},
+	"year":    {months: 12},
+	"decade":  {months: 120},
+}
+
+// ParseInterval parses an interval consisting of pairs of amounts and units
+// (e.g., `1 year`, `3 months` or `1 day 12 hours`) into a postgres interval.
+// Since the interval is used as size for buckets, it needs to be positive and
+// may not combine units measured in months with shorter units
+func ParseInterval(value string) (pgtype.Interval, error) {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) == 0 {
+		return pgtype.Interval{}, errors.New("empty interval")
+	}
+	if len(fields)%2 != 0 {
+		return pgtype.Interval{}, fmt.Errorf("interval '%s' needs to consist of amounts and units", value)
+	}
+
+	var months, microseconds int64
+	for i := 0; i < len(fields); i += 2 {
+		amount, err := strconv.ParseInt(fields[i], 10, 32)
+		if err != nil || amount <= 0 {
+			return pgtype.Interval{}, fmt.Errorf("'%s' is not a positive amount", fields[i])
+		}
+		unit, known := intervalUnits[strings.TrimSuffix(fields[i+1], "s")]
+		if !known {
+			return pgtype.Interval{}, fmt.Errorf("unknown unit '%s'", fields[i+1])
+		}
+		if unit.microseconds > 0 && amount > (math.MaxInt64-microseconds)/unit.microseconds {
+			return pgtype.Interval{}, fmt.Errorf("interval '%s' is too large", value)
+		}
+		months += amount * unit.months
+		microseconds += amount * unit.microseconds
+	}
+
+	if months > math.MaxInt32 {
+		return pgtype.Interval{}, fmt.Errorf("interval '%s' is too large", value)
+	}
+	if months > 0 && microseconds > 0 {
+		return pgtype.Interval{}, fmt.Errorf("interval '%s' may not combine months or years with shorter units", value)
+	}
+	return pgtype.Interval{
+		Months:       int32(months),
+		Microseconds: microseconds,
+		Valid:        true,
+	}, nil
+}
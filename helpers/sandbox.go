@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// ErrSandbox is returned if the sandbox of an algorithm could not be set up
+var ErrSandbox = errors.New("unable to sandbox algorithm")
+
+const (
+	// DefaultSandboxCPUTime contains the processor time available to sandboxed
+	// algorithms which do not configure their own limit
+	DefaultSandboxCPUTime = 10 * time.Minute
+
+	// DefaultSandboxMemory contains the address space in mebibytes available
+	// to sandboxed algorithms which do not configure their own limit
+	DefaultSandboxMemory = 4096
+
+	// DefaultSandboxOpenFiles contains the number of files sandboxed
+	// algorithms may open at the same time if they do not configure their own
+	// limit
+	DefaultSandboxOpenFiles = 256
+
+	// DefaultSandboxUser contains the id of the user and group executing
+	// sandboxed algorithms which do not configure their own user. It is the
+	// id of the user nobody
+	DefaultSandboxUser = 65534
+)
+
+// Sandbox contains the restrictions applied to an algorithm while it is
+// executed. A nil sandbox executes the algorithm without restrictions.
+// On Linux, the algorithm runs in its own mount, process and network namespace
+// with a private temporary directory and read-only views of its working
+// directory and its directory. Other platforms ignore the sandbox
+type Sandbox struct {
+	// CPUTime limits the processor time used by the algorithm
+	CPUTime time.Duration `json:"cpuTime"`
+
+	// Memory limits the address space of the algorithm in bytes
+	Memory uint64 `json:"memory"`
+
+	// OpenFiles limits the number of files the algorithm may open
+	OpenFiles uint64 `json:"openFiles"`
+
+	// Network allows the algorithm to access the network of the service
+	Network bool `json:"network"`
+
+	// User contains the id of the user executing the algorithm
+	User int `json:"user"`
+
+	// Group contains the id of the group executing the algorithm
+	Group int `json:"group"`
+}
+
+// SandboxSupported reports if the platform supports executing algorithms in
+// a sandbox
+func SandboxSupported() bool {
+	return runtime.GOOS == "linux"
+}
+
+// NewSandbox creates the sandbox described by the configuration and uses the
+// defaults for unset values. If the configuration disables the sandbox, nil
+// is returned
+func NewSandbox(configuration types.SandboxConfiguration) (*Sandbox, error) {
+	if configuration.Disabled {
+		return nil, nil
+	}
+	sandbox := &Sandbox{
+		CPUTime:   DefaultSandboxCPUTime,
+		Memory:    DefaultSandboxMemory << 20,
+		OpenFiles: DefaultSandboxOpenFiles,
+		Network:   configuration.Network,
+		User:      DefaultSandboxUser,
+		Group:     DefaultSandboxUser,
+	}
+	if configuration.CPUTime != "" {
+		cpuTime, err := time.ParseDuration(configuration.CPUTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu time: %w", err)
+		}
+		if cpuTime < time.Second {
+			return nil, errors.New("cpu time needs to be at least one second")
+		}
+		sandbox.CPUTime = cpuTime
+	}
+	switch {
+	case configuration.Memory < 0:
+		return nil, errors.New("memory may not be negative")
+	case configuration.Memory > 0:
+		sandbox.Memory = uint64(configuration.Memory) << 20
+	}
+	switch {
+	case configuration.OpenFiles < 0:
+		return nil, errors.New("open files may not be negative")
+	case configuration.OpenFiles > 0:
+		sandbox.OpenFiles = uint64(configuration.OpenFiles)
+	}
+	if configuration.User < 0 || configuration.Group < 0 {
+		return nil, errors.New("user and group may not be negative")
+	}
+	if configuration.User > 0 {
+		sandbox.User = configuration.User
+	}
+	if configuration.Group > 0 {
+		sandbox.Group = configuration.Group
+	}
+	return sandbox, nil
+}
@@ -0,0 +1,283 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxCommand is used as name of the process which sets up the sandbox.
+// The service executes itself under this name and the process is replaced by
+// the algorithm once the sandbox is set up
+const sandboxCommand = "usage-forecasts-sandbox"
+
+// sandboxEnvironment contains the name of the environment variable used to
+// pass the sandbox to the process setting it up
+const sandboxEnvironment = "USAGE_FORECASTS_SANDBOX"
+
+// sandboxExitCode is used by the process setting up the sandbox if the
+// sandbox could not be set up. The error is written to the error output
+// using the sandboxErrorPrefix
+const sandboxExitCode = 125
+
+// sandboxErrorPrefix prefixes the error written by the process setting up
+// the sandbox
+const sandboxErrorPrefix = "sandbox: "
+
+// sandboxPath contains the search path used by sandboxed algorithms
+const sandboxPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// sandboxHome contains the name of the directory created within the private
+// temporary directory which is used as home and temporary directory of the
+// algorithm
+const sandboxHome = "sandbox-home"
+
+// init sets up the sandbox and replaces the process with the algorithm if the
+// service has been executed as sandbox. Since the packages used by the main
+// package are initialized first, the initialization of the service is not
+// executed in this case
+func init() {
+	if len(os.Args) == 0 || os.Args[0] != sandboxCommand {
+		return
+	}
+	err := enterSandbox(os.Args[1:])
+	_, _ = fmt.Fprintf(os.Stderr, "%s%v\n", sandboxErrorPrefix, err)
+	os.Exit(sandboxExitCode)
+}
+
+// sandboxedCommand creates the command which executes the algorithm within
+// the sandbox. The working directory of the command needs to be set to the
+// directory containing the files passed to the algorithm
+func sandboxedCommand(ctx context.Context, sandbox *Sandbox, algorithmPath, dataFile, outputFile, parameterFile string) (*exec.Cmd, error) {
+	configuration, err := json.Marshal(sandbox)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSandbox, err)
+	}
+	// the interpreter is resolved using the search path of the service since
+	// the sandbox uses its own search path
+	interpreterPath, err := exec.LookPath(interpreter)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find interpreter: %w", err)
+	}
+	interpreterPath, err = filepath.Abs(interpreterPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find interpreter: %w", err)
+	}
+	// the service is executed using the link in the proc filesystem to be
+	// independent of the path it has been started with
+	cmd := exec.CommandContext(ctx, "/proc/self/exe")
+	cmd.Args = []string{sandboxCommand, interpreterPath, algorithmPath, dataFile, outputFile, parameterFile}
+	cmd.Env = []string{sandboxEnvironment + "=" + string(configuration)}
+	// in its own process namespace, the algorithm can neither see nor signal
+	// the processes of the service and other forecasts
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid:    true,
+		Cloneflags: syscall.CLONE_NEWNS | syscall.CLONE_NEWPID,
+	}
+	if !sandbox.Network {
+		// a new network namespace only contains a loopback interface which
+		// is not connected to the network of the service
+		cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNET
+	}
+	return cmd, nil
+}
+
+// enterSandbox sets up the sandbox for the algorithm and replaces the current
+// process with the interpreter executing the algorithm. The arguments contain
+// the paths of the interpreter, the algorithm, the data file, the output file
+// and the parameter file. It only returns if the sandbox could not be set up
+// or the algorithm could not be executed.
+// The process already runs in its own mount, process and network namespace.
+// Within the mount namespace, the temporary directory is replaced by a
+// private filesystem only containing the working directory and the directory
+// of the algorithm, which are both mounted read-only. The algorithm writes its
+// output through a file descriptor opened before the mounts and may only
+// write other files into a private directory within the temporary directory.
+// Afterward, the privileges are dropped and the limits are applied
+func enterSandbox(arguments []string) error {
+	if len(arguments) != 5 {
+		return errors.New("expected interpreter, algorithm, data, output and parameter file")
+	}
+	var sandbox Sandbox
+	err := json.Unmarshal([]byte(os.Getenv(sandboxEnvironment)), &sandbox)
+	if err != nil {
+		return fmt.Errorf("invalid sandbox configuration: %w", err)
+	}
+	interpreterPath, algorithmPath, outputFile := arguments[0], arguments[1], arguments[3]
+	algorithmDirectory := filepath.Dir(algorithmPath)
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("unable to determine working directory: %w", err)
+	}
+	temporaryDirectory := filepath.Dir(workingDirectory)
+	if temporaryDirectory == "/" {
+		return errors.New("working directory may not be located in the root directory")
+	}
+
+	// the working directory is owned by the user executing the algorithm to
+	// allow it to read its input and open its output
+	err = filepath.WalkDir(workingDirectory, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, sandbox.User, sandbox.Group)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to change owner of working directory: %w", err)
+	}
+
+	// changes to the mounts must not propagate to the namespace of the
+	// service
+	err = syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, "")
+	if err != nil {
+		return fmt.Errorf("unable to make mounts private: %w", err)
+	}
+
+	// the directories are opened before replacing the temporary directory
+	// since they may be located within it
+	workingDirectoryFd, err := syscall.Open(workingDirectory, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("unable to open working directory: %w", err)
+	}
+	algorithmDirectoryFd, err := syscall.Open(algorithmDirectory, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("unable to open algorithm directory: %w", err)
+	}
+	// the output file is kept open for the algorithm, which reopens it using
+	// the proc filesystem. since the descriptor refers to the writable mount
+	// of the service, the output can be written although the working
+	// directory is read-only within the sandbox
+	outputFd, err := syscall.Open(outputFile, syscall.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("unable to open output file: %w", err)
+	}
+	arguments[3] = "/proc/self/fd/" + strconv.Itoa(outputFd)
+
+	// the temporary directory is replaced to hide the working directories of
+	// other forecasts. files written into it count towards the memory limit
+	options := fmt.Sprintf("mode=0755,size=%d", sandbox.Memory)
+	err = syscall.Mount("tmpfs", temporaryDirectory, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, options)
+	if err != nil {
+		return fmt.Errorf("unable to replace temporary directory: %w", err)
+	}
+	err = bindDirectory(workingDirectoryFd, workingDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to mount working directory: %w", err)
+	}
+	err = bindDirectory(algorithmDirectoryFd, algorithmDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to mount algorithm directory: %w", err)
+	}
+	_ = syscall.Close(workingDirectoryFd)
+	_ = syscall.Close(algorithmDirectoryFd)
+	// the working directory has been replaced by the mount
+	err = syscall.Chdir(workingDirectory)
+	if err != nil {
+		return fmt.Errorf("unable to enter working directory: %w", err)
+	}
+
+	// the home directory is the only directory the algorithm may write to
+	homeDirectory := filepath.Join(temporaryDirectory, sandboxHome)
+	err = os.Mkdir(homeDirectory, 0o700)
+	if err == nil {
+		err = os.Lchown(homeDirectory, sandbox.User, sandbox.Group)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to create home directory: %w", err)
+	}
+
+	// the proc filesystem of the service shows every process of the host and
+	// is replaced by one only showing the processes of the sandbox
+	err = syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+	if err != nil {
+		return fmt.Errorf("unable to mount proc filesystem: %w", err)
+	}
+
+	err = syscall.Setgroups(nil)
+	if err != nil {
+		return fmt.Errorf("unable to drop supplementary groups: %w", err)
+	}
+	err = syscall.Setgid(sandbox.Group)
+	if err != nil {
+		return fmt.Errorf("unable to change group: %w", err)
+	}
+	err = syscall.Setuid(sandbox.User)
+	if err != nil {
+		return fmt.Errorf("unable to change user: %w", err)
+	}
+
+	// the soft limit of the processor time sends SIGXCPU to the algorithm.
+	// as first process of its namespace, the algorithm ignores it unless it
+	// handles the signal and is killed by the hard limit one second later
+	cpuTime := uint64(sandbox.CPUTime.Seconds())
+	limits := map[int]syscall.Rlimit{
+		syscall.RLIMIT_CPU:    {Cur: cpuTime, Max: cpuTime + 1},
+		syscall.RLIMIT_AS:     {Cur: sandbox.Memory, Max: sandbox.Memory},
+		syscall.RLIMIT_NOFILE: {Cur: sandbox.OpenFiles, Max: sandbox.OpenFiles},
+	}
+	for resource, limit := range limits {
+		err = syscall.Setrlimit(resource, &limit)
+		if err != nil {
+			return fmt.Errorf("unable to set resource limit %d: %w", resource, err)
+		}
+	}
+
+	// the environment of the service is not passed on since it contains the
+	// credentials of the database
+	environment := []string{
+		"PATH=" + sandboxPath,
+		"HOME=" + homeDirectory,
+		"TMPDIR=" + homeDirectory,
+		"LANG=C.UTF-8",
+		"PYTHONDONTWRITEBYTECODE=1",
+		"USER=" + strconv.Itoa(sandbox.User),
+	}
+	// setuid executables must not regain the dropped privileges
+	err = unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0)
+	if err != nil {
+		return fmt.Errorf("unable to prevent gaining privileges: %w", err)
+	}
+	err = syscall.Exec(interpreterPath, arguments, environment)
+	return fmt.Errorf("unable to execute algorithm: %w", err)
+}
+
+// bindDirectory mounts the directory referenced by the file descriptor
+// read-only at the path and creates the path if necessary. Neither setuid
+// executables nor device files can be used from the mount
+func bindDirectory(fd int, path string) error {
+	err := os.MkdirAll(path, 0o755)
+	if err != nil {
+		return err
+	}
+	source := "/proc/self/fd/" + strconv.Itoa(fd)
+	err = syscall.Mount(source, path, "", syscall.MS_BIND|syscall.MS_REC, "")
+	if err != nil {
+		return err
+	}
+	// the flags of bind mounts can only be changed by remounting them
+	return syscall.Mount("", path, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, "")
+}
+
+// sandboxError converts the error of a sandboxed algorithm into an ErrSandbox
+// if the sandbox could not be set up
+func sandboxError(err error) error {
+	var executionError *ExecutionError
+	if !errors.As(err, &executionError) || executionError.ExitCode != sandboxExitCode {
+		return err
+	}
+	message := strings.TrimSpace(executionError.Stderr)
+	if !strings.HasPrefix(message, sandboxErrorPrefix) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", ErrSandbox, strings.TrimPrefix(message, sandboxErrorPrefix))
+}
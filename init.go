@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	wisdomType "github.com/wisdom-oss/commonTypes/v2"
@@ -17,7 +19,14 @@ import (
 
 	_ "github.com/wisdom-oss/go-healthcheck/client"
 
+	"github.com/wisdom-oss/service-usage-forecasts/auth"
+	"github.com/wisdom-oss/service-usage-forecasts/cache"
 	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/jobs"
+	"github.com/wisdom-oss/service-usage-forecasts/native"
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+	"github.com/wisdom-oss/service-usage-forecasts/registry"
+	"github.com/wisdom-oss/service-usage-forecasts/scheduler"
 )
 
 // init is executed at every startup of the microservice and is always executed
@@ -30,8 +39,16 @@ func init() {
 	}
 	configureLogger()
 	loadServiceConfiguration()
+	loadAuthConfiguration()
+	loadErrorCatalogue()
 	connectDatabase()
 	loadPreparedQueries()
+	prepareDatabase()
+	loadAlgorithms()
+	watchAlgorithms()
+	createScheduler()
+	createJobStore()
+	createCache()
 	log.Info().Msg("initialization process finished")
 }
 
@@ -95,6 +112,37 @@ func loadServiceConfiguration() {
 	log.Info().Msg("loaded service configuration from environment")
 }
 
+// loadAuthConfiguration loads the authorization configuration from the file
+// specified by the AUTH_CONFIG_FILE_LOCATION environment variable.
+// The service does not start with an invalid configuration to prevent it from
+// running without the intended authorization.
+func loadAuthConfiguration() {
+	log.Info().Msg("loading authorization configuration")
+	var err error
+	globals.AuthConfig, err = auth.LoadConfig(globals.Environment["AUTH_CONFIG_FILE_LOCATION"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to load authorization configuration")
+	}
+	if !globals.AuthConfig.EnableAuth {
+		log.Warn().Msg("authorization disabled, only the management of algorithms requires authorization")
+	}
+	if globals.AuthConfig.DisableManagement {
+		log.Info().Msg("management of algorithms and on-demand forecasts disabled")
+	}
+}
+
+// loadErrorCatalogue loads the catalogue of errors returned by the service
+// from the file specified by the ERROR_FILE_LOCATION environment variable.
+// The catalogue needs to define every error code used by the service.
+func loadErrorCatalogue() {
+	log.Info().Msg("loading error catalogue")
+	var err error
+	globals.Errors, err = problems.Load(globals.Environment["ERROR_FILE_LOCATION"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to load error catalogue")
+	}
+}
+
 // connectDatabase uses the previously read environment variables to connect the
 // microservice to the PostgreSQL database used as the backend for all WISdoM
 // services
@@ -135,3 +183,151 @@ func loadPreparedQueries() {
 		log.Fatal().Err(err).Msg("failed to load prepared queries")
 	}
 }
+
+// prepareDatabase creates the schema and tables used to store the history of
+// the forecasts if they do not exist yet.
+func prepareDatabase() {
+	log.Info().Msg("preparing database for forecast history")
+	for _, queryName := range []string{"create-forecasts-schema", "create-runs-table"} {
+		query, err := globals.SqlQueries.Raw(queryName)
+		if err != nil {
+			log.Fatal().Err(err).Str("query", queryName).Msg("unable to load query")
+		}
+		_, err = globals.Db.Exec(context.Background(), query)
+		if err != nil {
+			log.Fatal().Err(err).Str("query", queryName).Msg("unable to prepare database")
+		}
+	}
+}
+
+// loadAlgorithms creates the algorithm registry and loads the algorithms
+// stored in the directories specified by the INTERNAL_ALGORITHM_LOCATION and
+// EXTERNAL_ALGORITHM_LOCATION environment variables.
+// If an identifier exists in both directories, the internal algorithm takes
+// precedence and the external one is reported as invalid.
+// Algorithms with broken metadata are reported during the loading but do not
+// prevent the startup of the microservice.
+// Unless NATIVE_ALGORITHMS is disabled, the algorithms implemented natively
+// replace the scripts using the same identifiers.
+// If SANDBOX_ALGORITHMS is enabled, the scripts are executed in the sandbox
+// configured in their metadata. It is disabled by default since the sandbox
+// requires privileges which containers do not have by default.
+func loadAlgorithms() {
+	log.Info().Msg("loading algorithms")
+	// the external algorithm location may not exist on fresh deployments,
+	// therefore create it before using it
+	err := os.MkdirAll(globals.Environment["EXTERNAL_ALGORITHM_LOCATION"], 0o755)
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to create external algorithm location")
+	}
+	globals.Algorithms = registry.New(
+		registry.Source{
+			Directory: globals.Environment["INTERNAL_ALGORITHM_LOCATION"],
+			Origin:    registry.OriginInternal,
+		},
+		registry.Source{
+			Directory: globals.Environment["EXTERNAL_ALGORITHM_LOCATION"],
+			Origin:    registry.OriginExternal,
+		},
+	)
+	useNative, err := strconv.ParseBool(globals.Environment["NATIVE_ALGORITHMS"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid value for native algorithms configured")
+	}
+	if useNative {
+		globals.Algorithms.UseNative(native.Implementations()...)
+	}
+	useSandbox, err := strconv.ParseBool(globals.Environment["SANDBOX_ALGORITHMS"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid value for algorithm sandbox configured")
+	}
+	if !useSandbox {
+		log.Warn().Msg("!!! SANDBOX DISABLED !!! algorithms are executed with the privileges of the service")
+		log.Warn().Msg("uploaded algorithms may access the database credentials, the network and the files of the service")
+		log.Warn().Msg("on-demand forecasts are unavailable, set SANDBOX_ALGORITHMS to true to enable the sandbox")
+		globals.Algorithms.DisableSandbox()
+	}
+	err = globals.Algorithms.Load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load algorithms")
+	}
+	globals.AlgorithmTimeout, err = time.ParseDuration(globals.Environment["ALGORITHM_TIMEOUT"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid default algorithm timeout configured")
+	}
+	globals.OnDemandTimeout, err = time.ParseDuration(globals.Environment["ON_DEMAND_TIMEOUT"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid on-demand timeout configured")
+	}
+	if globals.OnDemandTimeout < time.Second {
+		log.Fatal().Dur("timeout", globals.OnDemandTimeout).Msg("on-demand timeout needs to be at least one second")
+	}
+	globals.DebugAlgorithms, err = strconv.ParseBool(globals.Environment["DEBUG_ALGORITHMS"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid value for algorithm debugging configured")
+	}
+}
+
+// watchAlgorithms starts watching the algorithm directories for changes and
+// reloads the algorithm registry after the changes settled.
+// The delay between the last change and the reload is configured using the
+// ALGORITHM_RELOAD_DELAY environment variable.
+func watchAlgorithms() {
+	delay, err := time.ParseDuration(globals.Environment["ALGORITHM_RELOAD_DELAY"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid delay for algorithm reloads configured")
+	}
+	err = globals.Algorithms.Watch(context.Background(), delay)
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to watch algorithm directories")
+	}
+	log.Info().Msg("watching algorithm directories for changes")
+}
+
+// createScheduler creates the scheduler limiting the parallel algorithm
+// executions. The limits are read from the MAX_PARALLEL_FORECASTS and
+// MAX_QUEUED_FORECASTS environment variables.
+func createScheduler() {
+	parallelExecutions, err := strconv.Atoi(globals.Environment["MAX_PARALLEL_FORECASTS"])
+	if err != nil || parallelExecutions < 1 {
+		log.Fatal().Err(err).Msg("invalid number of parallel forecasts configured")
+	}
+	queueSize, err := strconv.Atoi(globals.Environment["MAX_QUEUED_FORECASTS"])
+	if err != nil || queueSize < 1 {
+		log.Fatal().Err(err).Msg("invalid number of queued forecasts configured")
+	}
+	globals.Scheduler = scheduler.New(parallelExecutions, queueSize)
+	log.Info().Int("parallel", parallelExecutions).Int("queue", queueSize).Msg("created execution scheduler")
+}
+
+// createJobStore creates the store for asynchronous forecasts. Finished jobs
+// are kept for the duration configured by the JOB_RETENTION environment
+// variable.
+func createJobStore() {
+	retention, err := time.ParseDuration(globals.Environment["JOB_RETENTION"])
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid job retention configured")
+	}
+	globals.Jobs = jobs.New(retention)
+}
+
+// createCache creates the in-memory cache for the outputs of the algorithms.
+// The CACHE_TTL environment variable configures how long an output is cached
+// and CACHE_SIZE limits the number of cached outputs. Setting CACHE_SIZE to
+// zero disables the cache.
+func createCache() {
+	size, err := strconv.Atoi(globals.Environment["CACHE_SIZE"])
+	if err != nil || size < 0 {
+		log.Fatal().Err(err).Msg("invalid cache size configured")
+	}
+	if size == 0 {
+		log.Info().Msg("caching of forecasts disabled")
+		return
+	}
+	ttl, err := time.ParseDuration(globals.Environment["CACHE_TTL"])
+	if err != nil || ttl <= 0 {
+		log.Fatal().Err(err).Msg("invalid cache ttl configured")
+	}
+	globals.Cache = cache.NewMemory(ttl, size)
+	log.Info().Int("size", size).Dur("ttl", ttl).Msg("created forecast cache")
+}
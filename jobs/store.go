@@ -0,0 +1,148 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// Runner executes the forecast of a job. It needs to call started as soon as
+// the algorithm is executed
+type Runner func(ctx context.Context, started func()) ([]byte, error)
+
+// Store keeps track of the asynchronous forecasts. Finished jobs are removed
+// from the store after the retention time
+type Store struct {
+	// lock protects the jobs
+	lock sync.RWMutex
+
+	// jobs contains the jobs indexed by their id
+	jobs map[string]*job
+
+	// retention contains the duration for which finished jobs are kept
+	retention time.Duration
+}
+
+// job contains the information about a job and the function used to cancel
+// its execution
+type job struct {
+	information types.ForecastJob
+	cancel      context.CancelFunc
+}
+
+// New creates a new store keeping finished jobs for the retention duration
+func New(retention time.Duration) *Store {
+	return &Store{
+		jobs:      make(map[string]*job),
+		retention: retention,
+	}
+}
+
+// Submit creates a new job for the algorithm and executes the runner in the
+// background. The returned job contains the id used to query the job
+func (s *Store) Submit(algorithm string, runner Runner) types.ForecastJob {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{
+		information: types.ForecastJob{
+			ID:        uuid.NewString(),
+			Algorithm: algorithm,
+			Status:    types.JobQueued,
+			CreatedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+
+	s.lock.Lock()
+	s.jobs[j.information.ID] = j
+	information := j.information
+	s.lock.Unlock()
+
+	go s.execute(ctx, j, runner)
+	return information
+}
+
+// Get returns the current state of the job
+func (s *Store) Get(id string) (types.ForecastJob, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	j, found := s.jobs[id]
+	if !found {
+		return types.ForecastJob{}, false
+	}
+	return j.information, true
+}
+
+// Cancel stops the execution of a job that has not finished yet and marks it
+// as cancelled. Finished jobs are removed from the store instead. The returned
+// job contains the state after the cancellation
+func (s *Store) Cancel(id string) (types.ForecastJob, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, found := s.jobs[id]
+	if !found {
+		return types.ForecastJob{}, false
+	}
+	if j.information.Status.Finished() {
+		delete(s.jobs, id)
+		return j.information, true
+	}
+	j.cancel()
+	s.finish(j, types.JobCancelled, nil, "")
+	return j.information, true
+}
+
+// execute runs the job and records its result
+func (s *Store) execute(ctx context.Context, j *job, runner Runner) {
+	defer j.cancel()
+	result, err := runner(ctx, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if j.information.Status != types.JobQueued {
+			return
+		}
+		now := time.Now()
+		j.information.Status = types.JobRunning
+		j.information.StartedAt = &now
+	})
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// jobs that have been cancelled already have been finished
+	if j.information.Status.Finished() {
+		return
+	}
+	switch {
+	case err != nil && errors.Is(err, context.Canceled):
+		s.finish(j, types.JobCancelled, nil, "")
+	case err != nil:
+		log.Warn().Err(err).Str("job", j.information.ID).Msg("forecast job failed")
+		s.finish(j, types.JobFailed, nil, err.Error())
+	default:
+		s.finish(j, types.JobSucceeded, result, "")
+	}
+}
+
+// finish sets the final state of the job and schedules its removal. The lock
+// of the store needs to be held while calling finish
+func (s *Store) finish(j *job, status types.JobStatus, result []byte, reason string) {
+	now := time.Now()
+	j.information.Status = status
+	j.information.FinishedAt = &now
+	j.information.Result = result
+	j.information.Error = reason
+
+	id := j.information.ID
+	time.AfterFunc(s.retention, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if current, found := s.jobs[id]; found && current == j {
+			delete(s.jobs, id)
+		}
+	})
+}
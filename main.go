@@ -11,11 +11,11 @@ import (
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/httplog"
 	"github.com/rs/zerolog/log"
-	wisdomMiddleware "github.com/wisdom-oss/microservice-middlewares/v4"
-
-	"github.com/wisdom-oss/service-usage-forecasts/routes"
 
+	"github.com/wisdom-oss/service-usage-forecasts/auth"
 	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+	"github.com/wisdom-oss/service-usage-forecasts/routes"
 )
 
 // the main function bootstraps the http server and handlers used for this
@@ -31,12 +31,39 @@ func main() {
 	router.Use(chiMiddleware.RequestID)
 	router.Use(chiMiddleware.RealIP)
 	router.Use(httplog.Handler(l))
-	router.Use(wisdomMiddleware.ErrorHandler)
-	// now add the authorization middleware to the router
-	//router.Use(wisdomMiddleware.Authorization(globals.ServiceName))
-	// now mount the admin router
-	router.HandleFunc("/", routes.InformationRoute)
-	router.HandleFunc("/{algorithm-name}", routes.PredefinedForecast)
+	router.Use(problems.ErrorHandler(globals.Errors))
+	// the error catalogue is public to allow client developers to handle
+	// the errors of the service
+	router.Get("/errors", routes.ErrorCatalogue)
+	// now add the routes grouped by the scope required to access them. the
+	// scopes are only enforced if the authorization is enabled, except for
+	// the management of uploaded algorithms and the on-demand forecasts which
+	// always require an authorized user and are only available if they are
+	// not disabled
+	router.Group(func(r chi.Router) {
+		r.Use(auth.Middleware(globals.AuthConfig, auth.ScopeRead))
+		r.Get("/", routes.InformationRoute)
+		r.Get("/invalid-algorithms", routes.InvalidAlgorithms)
+		r.Get("/runs", routes.ListRuns)
+		r.Get("/runs/{run-id}", routes.GetRun)
+	})
+	router.Group(func(r chi.Router) {
+		r.Use(auth.Middleware(globals.AuthConfig, auth.ScopeRun))
+		r.Get("/{algorithm-name}", routes.PredefinedForecast)
+		r.Post("/{algorithm-name}", routes.PredefinedForecast)
+		r.Post("/{algorithm-name}/jobs", routes.SubmitForecastJob)
+		r.Get("/jobs/{job-id}", routes.GetForecastJob)
+		r.Delete("/jobs/{job-id}", routes.CancelForecastJob)
+	})
+	if !globals.AuthConfig.DisableManagement {
+		router.Group(func(r chi.Router) {
+			r.Use(auth.Middleware(globals.AuthConfig, auth.ScopeManage))
+			r.Post("/", routes.UploadAlgorithm)
+			r.Post("/on-demand", routes.OnDemandForecast)
+			r.Put("/{algorithm-name}", routes.ReplaceAlgorithm)
+			r.Delete("/{algorithm-name}", routes.DeleteAlgorithm)
+		})
+	}
 
 	// now boot up the service
 	// Configure the HTTP server
@@ -0,0 +1,17 @@
+-- This migration belongs to the owner of the wisdom.timeseries.water_usage
+-- table and is not executed by this service.
+--
+-- The usage queries select the municipalities using the starts-with operator
+-- (municipality ^@ ANY ($1)), which can only use an index supporting prefix
+-- searches. Without it, every forecast scans the usages of all municipalities.
+--
+-- The index is built concurrently to keep the table writable during the
+-- build. Therefore, the statement can not be executed within a transaction,
+-- e.g.:
+--
+--   psql --dbname wisdom --file water-usage-municipality-index.sql
+--
+-- If the build fails, the invalid index needs to be dropped using
+-- DROP INDEX CONCURRENTLY before executing the migration again.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS water_usage_municipality_prefix_idx
+    ON timeseries.water_usage USING spgist (municipality);
@@ -0,0 +1,409 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// maximalOrder and maximalSeasonalOrder limit the orders evaluated by the
+// automatic order selection of the ARIMA model
+const (
+	maximalOrder         = 2
+	maximalSeasonalOrder = 1
+)
+
+// arima forecasts monthly usages using a seasonal ARIMA model
+var arima = Implementation{
+	Identifier: "arima",
+	Metadata: types.AlgorithmMetadata{
+		DisplayName: "Seasonal ARIMA",
+		Description: "A seasonal autoregressive integrated moving average model of the monthly usages. The orders of the model are selected automatically using the AIC",
+		Parameters:  seasonalParameterDefinitions(),
+		UseBuckets:  true,
+		BucketSize:  "1 month",
+	},
+	Forecast: seasonal(fitArima),
+}
+
+// arimaModel describes a seasonal ARIMA model. The seasonal terms are added to
+// the non-seasonal terms instead of multiplying the polynomials to keep the
+// estimation simple
+type arimaModel struct {
+	p, d, q    int
+	P, D, Q    int
+	season     int
+	mean       float64
+	ar, ma     []float64
+	seasonalAR float64
+	seasonalMA float64
+}
+
+// fitArima fits a seasonal ARIMA model to the monthly usages. The differencing
+// orders are selected using the variance and autocorrelation of the
+// differenced usages and the remaining orders by minimizing the AIC of the
+// models estimated using conditional sum of squares
+func fitArima(ctx context.Context, usages []float64, parameters seasonalParameters) (seasonalFit, error) {
+	season := parameters.SeasonLength
+
+	// now select the orders of differencing
+	seasonalDifferencing := 0
+	if len(usages) >= 3*season && autocorrelation(difference(usages, 1), season) > 0.3 {
+		seasonalDifferencing = 1
+	}
+	seasonallyDifferenced := usages
+	if seasonalDifferencing == 1 {
+		seasonallyDifferenced = difference(usages, season)
+	}
+	differencing := 0
+	if differenced := difference(seasonallyDifferenced, 1); variance(differenced) < variance(seasonallyDifferenced) {
+		differencing = 1
+	}
+	stationary := seasonallyDifferenced
+	if differencing == 1 {
+		stationary = difference(seasonallyDifferenced, 1)
+	}
+
+	// series which are differenced twice do not contain a constant
+	var mean float64
+	if differencing+seasonalDifferencing < 2 {
+		for _, value := range stationary {
+			mean += value / float64(len(stationary))
+		}
+	}
+	centered := make([]float64, len(stationary))
+	for i, value := range stationary {
+		centered[i] = value - mean
+	}
+
+	// now estimate every combination of orders and keep the one with the
+	// lowest AIC
+	var best arimaModel
+	bestCriterion := math.Inf(1)
+	for p := 0; p <= maximalOrder; p++ {
+		for q := 0; q <= maximalOrder; q++ {
+			for P := 0; P <= maximalSeasonalOrder; P++ {
+				for Q := 0; Q <= maximalSeasonalOrder; Q++ {
+					if (P > 0 || Q > 0) && len(centered) < 2*season+p+q {
+						continue
+					}
+					model := arimaModel{
+						p: p, d: differencing, q: q,
+						P: P, D: seasonalDifferencing, Q: Q,
+						season: season, mean: mean,
+					}
+					criterion, err := model.estimate(ctx, centered)
+					if err != nil {
+						return seasonalFit{}, err
+					}
+					if criterion < bestCriterion {
+						best, bestCriterion = model, criterion
+					}
+				}
+			}
+		}
+	}
+	if math.IsInf(bestCriterion, 1) {
+		return seasonalFit{}, fmt.Errorf("%w: no ARIMA model could be estimated", ErrUnsuitableData)
+	}
+
+	residuals, start := best.residuals(centered)
+	var sum float64
+	for _, residual := range residuals[start:] {
+		sum += residual * residual
+	}
+	sigma := math.Sqrt(sum / float64(len(residuals)-start))
+
+	// the one-step-ahead error of the usages equals the error of the
+	// differenced usages since the differencing only uses known usages
+	offset := len(usages) - len(centered)
+	fitted := make([]float64, len(usages))
+	for t := range fitted {
+		fitted[t] = math.NaN()
+		if t-offset >= start {
+			fitted[t] = usages[t] - residuals[t-offset]
+		}
+	}
+
+	forecast := best.forecast(usages, centered, residuals, parameters.Size)
+	psi := best.psiWeights(parameters.Size)
+	standardErrors := make([]float64, parameters.Size)
+	var accumulated float64
+	for h := range standardErrors {
+		accumulated += psi[h] * psi[h]
+		standardErrors[h] = sigma * math.Sqrt(accumulated)
+	}
+
+	return seasonalFit{
+		fitted:         fitted,
+		forecast:       forecast,
+		standardErrors: standardErrors,
+		description:    best.String(),
+	}, nil
+}
+
+// String returns the common notation of the model orders
+func (m arimaModel) String() string {
+	return fmt.Sprintf("ARIMA(%d,%d,%d)(%d,%d,%d)[%d]", m.p, m.d, m.q, m.P, m.D, m.Q, m.season)
+}
+
+// coefficients returns the number of estimated coefficients
+func (m arimaModel) coefficients() int {
+	return m.p + m.q + m.P + m.Q
+}
+
+// set assigns the coefficients from the vector used by the optimizer
+func (m *arimaModel) set(vector []float64) {
+	m.ar = vector[:m.p]
+	m.ma = vector[m.p : m.p+m.q]
+	m.seasonalAR, m.seasonalMA = 0, 0
+	index := m.p + m.q
+	if m.P > 0 {
+		m.seasonalAR = vector[index]
+		index++
+	}
+	if m.Q > 0 {
+		m.seasonalMA = vector[index]
+	}
+}
+
+// estimate estimates the coefficients of the model by minimizing the
+// conditional sum of squares and returns the AIC of the estimated model
+func (m *arimaModel) estimate(ctx context.Context, series []float64) (float64, error) {
+	count := m.coefficients()
+	if count > 0 {
+		lower := make([]float64, count)
+		upper := make([]float64, count)
+		for i := range lower {
+			lower[i], upper[i] = -0.99, 0.99
+		}
+		objective := func(vector []float64) float64 {
+			m.set(vector)
+			return m.sumOfSquares(series)
+		}
+		vector, err := minimize(ctx, objective, make([]float64, count), lower, upper)
+		if err != nil {
+			return 0, err
+		}
+		m.set(vector)
+	} else {
+		m.set(nil)
+	}
+
+	residuals, start := m.residuals(series)
+	observations := len(residuals) - start
+	if observations <= count+1 {
+		return math.Inf(1), nil
+	}
+	sum := m.sumOfSquares(series)
+	if sum <= 0 || math.IsNaN(sum) || math.IsInf(sum, 0) {
+		return math.Inf(1), nil
+	}
+	return float64(observations)*math.Log(sum/float64(observations)) + 2*float64(count+1), nil
+}
+
+// sumOfSquares returns the conditional sum of squared residuals
+func (m arimaModel) sumOfSquares(series []float64) float64 {
+	residuals, start := m.residuals(series)
+	var sum float64
+	for _, residual := range residuals[start:] {
+		sum += residual * residual
+	}
+	return sum
+}
+
+// residuals calculates the one-step-ahead errors of the model. The errors
+// before the first predictable value are assumed to be zero and the index of
+// the first predictable value is returned
+func (m arimaModel) residuals(series []float64) ([]float64, int) {
+	start := m.p
+	if m.P > 0 {
+		start = max(start, m.season)
+	}
+	residuals := make([]float64, len(series))
+	for t := start; t < len(series); t++ {
+		residuals[t] = series[t] - m.predict(series, residuals, t)
+	}
+	return residuals, min(start, len(series))
+}
+
+// predict returns the prediction for the value at the index using the values
+// and residuals before it
+func (m arimaModel) predict(series, residuals []float64, t int) float64 {
+	var prediction float64
+	for i, coefficient := range m.ar {
+		prediction += coefficient * series[t-i-1]
+	}
+	for j, coefficient := range m.ma {
+		if t-j-1 >= 0 {
+			prediction += coefficient * residuals[t-j-1]
+		}
+	}
+	if m.P > 0 && t-m.season >= 0 {
+		prediction += m.seasonalAR * series[t-m.season]
+	}
+	if m.Q > 0 && t-m.season >= 0 {
+		prediction += m.seasonalMA * residuals[t-m.season]
+	}
+	return prediction
+}
+
+// forecast predicts the following usages by forecasting the differenced
+// series and reverting the differencing afterward
+func (m arimaModel) forecast(usages, centered, residuals []float64, size int) []float64 {
+	series := append([]float64{}, centered...)
+	// future errors are unknown and therefore assumed to be zero
+	shocks := append([]float64{}, residuals...)
+	for h := 0; h < size; h++ {
+		t := len(series)
+		series = append(series, m.predict(series, shocks, t))
+		shocks = append(shocks, 0)
+	}
+
+	// now revert the differencing using the known usages
+	seasonallyDifferenced := usages
+	if m.D == 1 {
+		seasonallyDifferenced = difference(usages, m.season)
+	}
+	extended := append([]float64{}, seasonallyDifferenced...)
+	for h := 0; h < size; h++ {
+		value := series[len(centered)+h] + m.mean
+		if m.d == 1 {
+			value += extended[len(extended)-1]
+		}
+		extended = append(extended, value)
+	}
+	result := append([]float64{}, usages...)
+	for h := 0; h < size; h++ {
+		value := extended[len(seasonallyDifferenced)+h]
+		if m.D == 1 {
+			value += result[len(result)-m.season]
+		}
+		result = append(result, value)
+	}
+	return result[len(usages):]
+}
+
+// psiWeights calculates the weights of the past errors in the moving average
+// representation of the model including the differencing. They are used to
+// derive the variance of the forecasts
+func (m arimaModel) psiWeights(size int) []float64 {
+	// the autoregressive polynomial is multiplied with the differencing
+	// polynomials
+	ar := []float64{1}
+	for i, coefficient := range m.ar {
+		ar = setCoefficient(ar, i+1, -coefficient)
+	}
+	if m.P > 0 {
+		ar = setCoefficient(ar, m.season, coefficientOf(ar, m.season)-m.seasonalAR)
+	}
+	for i := 0; i < m.d; i++ {
+		ar = multiplyPolynomials(ar, []float64{1, -1})
+	}
+	if m.D == 1 {
+		seasonal := make([]float64, m.season+1)
+		seasonal[0], seasonal[m.season] = 1, -1
+		ar = multiplyPolynomials(ar, seasonal)
+	}
+
+	ma := []float64{1}
+	for j, coefficient := range m.ma {
+		ma = setCoefficient(ma, j+1, coefficient)
+	}
+	if m.Q > 0 {
+		ma = setCoefficient(ma, m.season, coefficientOf(ma, m.season)+m.seasonalMA)
+	}
+
+	psi := make([]float64, size)
+	for j := range psi {
+		value := coefficientOf(ma, j)
+		for k := 1; k <= j; k++ {
+			value -= coefficientOf(ar, k) * psi[j-k]
+		}
+		psi[j] = value
+	}
+	return psi
+}
+
+// setCoefficient sets the coefficient of the degree and extends the
+// polynomial if needed
+func setCoefficient(polynomial []float64, degree int, value float64) []float64 {
+	for len(polynomial) <= degree {
+		polynomial = append(polynomial, 0)
+	}
+	polynomial[degree] = value
+	return polynomial
+}
+
+// coefficientOf returns the coefficient of the degree or zero if the polynomial is
+// shorter
+func coefficientOf(polynomial []float64, degree int) float64 {
+	if degree < len(polynomial) {
+		return polynomial[degree]
+	}
+	return 0
+}
+
+// multiplyPolynomials multiplies two polynomials given by their coefficients
+func multiplyPolynomials(a, b []float64) []float64 {
+	product := make([]float64, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			product[i+j] += x * y
+		}
+	}
+	return product
+}
+
+// difference returns the differences between the values and the values lagged
+// by the lag
+func difference(values []float64, lag int) []float64 {
+	if len(values) <= lag {
+		return nil
+	}
+	differences := make([]float64, len(values)-lag)
+	for i := range differences {
+		differences[i] = values[i+lag] - values[i]
+	}
+	return differences
+}
+
+// variance returns the variance of the values
+func variance(values []float64) float64 {
+	if len(values) == 0 {
+		return math.Inf(1)
+	}
+	var mean float64
+	for _, value := range values {
+		mean += value / float64(len(values))
+	}
+	var sum float64
+	for _, value := range values {
+		sum += (value - mean) * (value - mean)
+	}
+	return sum / float64(len(values))
+}
+
+// autocorrelation returns the autocorrelation of the values at the lag
+func autocorrelation(values []float64, lag int) float64 {
+	if len(values) <= lag {
+		return 0
+	}
+	var mean float64
+	for _, value := range values {
+		mean += value / float64(len(values))
+	}
+	var covariance, total float64
+	for i, value := range values {
+		total += (value - mean) * (value - mean)
+		if i >= lag {
+			covariance += (value - mean) * (values[i-lag] - mean)
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return covariance / total
+}
@@ -0,0 +1,70 @@
+package native
+
+import (
+	"math"
+	"testing"
+)
+
+func TestArimaTracksSeasonalPattern(t *testing.T) {
+	tests := []struct {
+		name           string
+		multiplicative bool
+		tolerance      float64
+	}{
+		{name: "additive", tolerance: 0.02},
+		// the model is additive, so the growing amplitude is only followed
+		// approximately
+		{name: "multiplicative", multiplicative: true, tolerance: 0.05},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkSeasonalForecast(t, fitArima, test.multiplicative, seasonalParameters{}, test.tolerance)
+		})
+	}
+}
+
+func TestArimaIntervals(t *testing.T) {
+	checkIntervals(t, fitArima, seasonalParameters{})
+}
+
+func TestArimaPsiWeights(t *testing.T) {
+	tests := []struct {
+		name  string
+		model arimaModel
+		want  []float64
+	}{
+		{
+			name:  "white noise",
+			model: arimaModel{},
+			want:  []float64{1, 0, 0, 0},
+		},
+		{
+			name:  "random walk",
+			model: arimaModel{d: 1},
+			want:  []float64{1, 1, 1, 1},
+		},
+		{
+			name:  "autoregressive",
+			model: arimaModel{p: 1, ar: []float64{0.5}},
+			want:  []float64{1, 0.5, 0.25, 0.125},
+		},
+		{
+			name:  "moving average",
+			model: arimaModel{q: 1, ma: []float64{0.4}},
+			want:  []float64{1, 0.4, 0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			psi := test.model.psiWeights(len(test.want))
+			for i, weight := range test.want {
+				if math.Abs(psi[i]-weight) > 1e-12 {
+					t.Errorf("expected weights %v, got %v", test.want, psi)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// holtWinters forecasts seasonal monthly usages using the
+// Holt-Winters model
+var holtWinters = Implementation{
+	Identifier: "holt-winters",
+	Metadata: types.AlgorithmMetadata{
+		DisplayName: "Holt-Winters",
+		Description: "Triple exponential smoothing of the monthly usages with a linear trend and an additive or multiplicative seasonality. The smoothing parameters are selected automatically",
+		Parameters: func() map[string]types.Parameter {
+			parameters := seasonalParameterDefinitions()
+			parameters["seasonality"] = types.Parameter{
+				Description:  "Selects if the seasonal variation is constant (additive) or grows with the usage (multiplicative)",
+				DefaultValue: "additive",
+				Type:         "str",
+				Enums:        []string{"additive", "multiplicative"},
+			}
+			return parameters
+		}(),
+		UseBuckets: true,
+		BucketSize: "1 month",
+	},
+	Forecast: seasonal(fitHoltWinters),
+}
+
+// fitHoltWinters fits the Holt-Winters model with a seasonal component and a
+// linear trend to the monthly usages. The smoothing parameters are selected by
+// minimizing the squared one-step-ahead errors
+func fitHoltWinters(ctx context.Context, usages []float64, parameters seasonalParameters) (seasonalFit, error) {
+	multiplicative := parameters.Seasonality == "multiplicative"
+	if multiplicative {
+		for _, usage := range usages {
+			if usage <= 0 {
+				return seasonalFit{}, fmt.Errorf("%w: multiplicative seasonality requires positive usages", ErrUnsuitableData)
+			}
+		}
+	}
+	seasonLength := parameters.SeasonLength
+
+	objective := func(smoothing []float64) float64 {
+		fitted, _ := smoothHoltWinters(usages, seasonLength, multiplicative, smoothing[0], smoothing[1], smoothing[2], 0)
+		var sum float64
+		for i, value := range fitted {
+			if !math.IsNaN(value) {
+				sum += math.Pow(usages[i]-value, 2)
+			}
+		}
+		return sum
+	}
+	smoothing, err := minimize(ctx, objective,
+		[]float64{0.3, 0.1, 0.1},
+		[]float64{1e-4, 1e-4, 1e-4},
+		[]float64{1 - 1e-4, 1 - 1e-4, 1 - 1e-4},
+	)
+	if err != nil {
+		return seasonalFit{}, err
+	}
+	alpha, beta, gamma := smoothing[0], smoothing[1], smoothing[2]
+	fitted, forecast := smoothHoltWinters(usages, seasonLength, multiplicative, alpha, beta, gamma, parameters.Size)
+
+	// the variance of the one-step-ahead errors is used to derive the
+	// variance of the forecasts. for multiplicative seasonality, the errors
+	// are measured relative to the prediction
+	var sum float64
+	var count int
+	for i, value := range fitted {
+		if math.IsNaN(value) {
+			continue
+		}
+		residual := usages[i] - value
+		if multiplicative {
+			residual /= value
+		}
+		sum += residual * residual
+		count++
+	}
+	sigma := math.Sqrt(sum / float64(count))
+
+	standardErrors := make([]float64, len(forecast))
+	var accumulated float64
+	for h := 1; h <= len(forecast); h++ {
+		if h > 1 {
+			j := float64(h - 1)
+			weight := alpha * (1 + j*beta)
+			if (h-1)%seasonLength == 0 {
+				weight += gamma * (1 - alpha)
+			}
+			accumulated += weight * weight
+		}
+		standardErrors[h-1] = sigma * math.Sqrt(1+accumulated)
+		if multiplicative {
+			standardErrors[h-1] *= math.Abs(forecast[h-1])
+		}
+	}
+
+	seasonality := "additive"
+	if multiplicative {
+		seasonality = "multiplicative"
+	}
+	return seasonalFit{
+		fitted:         fitted,
+		forecast:       forecast,
+		standardErrors: standardErrors,
+		description:    fmt.Sprintf("Holt-Winters (%s, α=%.4f, β=%.4f, γ=%.4f)", seasonality, alpha, beta, gamma),
+	}, nil
+}
+
+// smoothHoltWinters applies the Holt-Winters equations to the usages and
+// returns the one-step-ahead predictions and the forecast for the following
+// months. The model is initialized using the first two seasons
+func smoothHoltWinters(usages []float64, seasonLength int, multiplicative bool, alpha, beta, gamma float64, size int) (fitted, forecast []float64) {
+	var firstSeason, secondSeason float64
+	for i := 0; i < seasonLength; i++ {
+		firstSeason += usages[i] / float64(seasonLength)
+		secondSeason += usages[seasonLength+i] / float64(seasonLength)
+	}
+	level := firstSeason
+	trend := (secondSeason - firstSeason) / float64(seasonLength)
+	seasons := make([]float64, seasonLength)
+	for i := range seasons {
+		if multiplicative {
+			seasons[i] = usages[i] / level
+		} else {
+			seasons[i] = usages[i] - level
+		}
+	}
+
+	fitted = make([]float64, len(usages))
+	for t := range fitted {
+		if t < seasonLength {
+			fitted[t] = math.NaN()
+			continue
+		}
+		season := seasons[t%seasonLength]
+		previousLevel := level
+		if multiplicative {
+			fitted[t] = (level + trend) * season
+			level = alpha*(usages[t]/season) + (1-alpha)*(level+trend)
+			seasons[t%seasonLength] = gamma*(usages[t]/level) + (1-gamma)*season
+		} else {
+			fitted[t] = level + trend + season
+			level = alpha*(usages[t]-season) + (1-alpha)*(level+trend)
+			seasons[t%seasonLength] = gamma*(usages[t]-level) + (1-gamma)*season
+		}
+		trend = beta*(level-previousLevel) + (1-beta)*trend
+	}
+
+	forecast = make([]float64, size)
+	for h := 1; h <= size; h++ {
+		season := seasons[(len(usages)+h-1)%seasonLength]
+		if multiplicative {
+			forecast[h-1] = (level + float64(h)*trend) * season
+		} else {
+			forecast[h-1] = level + float64(h)*trend + season
+		}
+	}
+	return fitted, forecast
+}
@@ -0,0 +1,47 @@
+package native
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHoltWintersTracksSeasonalPattern(t *testing.T) {
+	tests := []struct {
+		seasonality    string
+		multiplicative bool
+	}{
+		{seasonality: "additive"},
+		{seasonality: "multiplicative", multiplicative: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.seasonality, func(t *testing.T) {
+			parameters := seasonalParameters{Seasonality: test.seasonality}
+			checkSeasonalForecast(t, fitHoltWinters, test.multiplicative, parameters, 0.02)
+		})
+	}
+}
+
+func TestHoltWintersIntervals(t *testing.T) {
+	for _, seasonality := range []string{"additive", "multiplicative"} {
+		t.Run(seasonality, func(t *testing.T) {
+			checkIntervals(t, fitHoltWinters, seasonalParameters{Seasonality: seasonality})
+		})
+	}
+}
+
+func TestHoltWintersRejectsNonPositiveUsages(t *testing.T) {
+	for _, usage := range []float64{0, -5} {
+		usages := seasonalSeries(36, true)
+		usages[30] = usage
+
+		_, err := runSeasonal(t, fitHoltWinters, usages, seasonalParameters{Size: 12, Seasonality: "multiplicative"})
+		if !errors.Is(err, ErrUnsuitableData) {
+			t.Errorf("expected ErrUnsuitableData for usage %g, got %v", usage, err)
+		}
+		_, err = runSeasonal(t, fitHoltWinters, usages, seasonalParameters{Size: 12, Seasonality: "additive"})
+		if err != nil {
+			t.Errorf("unexpected error for usage %g with additive seasonality: %v", usage, err)
+		}
+	}
+}
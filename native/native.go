@@ -0,0 +1,68 @@
+// Package native contains algorithms which are implemented directly in the
+// service instead of being executed as external scripts. Since they do not
+// need an interpreter, they are used instead of the scripts with the same
+// identifier
+package native
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// ErrUnsuitableData is returned if the usage data can not be used by the
+// algorithm, e.g., since a data series is too short or the model could not be
+// fitted to it
+var ErrUnsuitableData = errors.New("usage data unsuitable for the algorithm")
+
+// Forecast calculates a forecast for the usage data using the parameters
+// supplied as json. The parameters have already been validated against the
+// metadata of the algorithm and contain the default values
+type Forecast func(ctx context.Context, usageDataPoints []types.UsageDataPoint, parameters []byte) (types.ForecastResult, error)
+
+// Implementation describes an algorithm implemented natively
+type Implementation struct {
+	// Identifier contains the identifier under which the algorithm is
+	// registered
+	Identifier string
+
+	// Metadata contains the metadata of the algorithm. It replaces the
+	// metadata of scripts using the same identifier
+	Metadata types.AlgorithmMetadata
+
+	// Forecast calculates the forecast
+	Forecast Forecast
+}
+
+// Implementations returns all algorithms implemented natively
+func Implementations() []Implementation {
+	return []Implementation{
+		linear,
+		exponential,
+		logarithmic,
+		holtWinters,
+		arima,
+	}
+}
+
+// groupByParameter describes the parameter used by the regressions to select
+// how the usage data is grouped into data series
+var groupByParameter = types.Parameter{
+	Description:  "The column the data should be grouped by before running the calculation",
+	DefaultValue: "municipal",
+	Type:         "str",
+	Enums:        []string{"municipal", "usageType"},
+}
+
+// sizeParameter describes the parameter used by the regressions to select the
+// number of forecasted years
+var sizeParameter = types.Parameter{
+	Description:  "The amount of years that shall be predicted, after the source data is available",
+	DefaultValue: 30,
+	Type:         "int",
+	Min:          &minimalSize,
+}
+
+// minimalSize contains the minimal number of forecasted years
+var minimalSize = 1
@@ -0,0 +1,189 @@
+package native
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// errSingularSystem is returned if the least squares problem has no unique
+// solution
+var errSingularSystem = fmt.Errorf("%w: unable to solve least squares problem", ErrUnsuitableData)
+
+// polynomial is a polynomial whose variable is shifted and scaled before
+// evaluating it. Fitting the polynomial to the scaled variable keeps the least
+// squares problem well-conditioned for large values like years
+type polynomial struct {
+	// coefficients contains the coefficients ordered by ascending degree
+	coefficients []float64
+
+	// offset is subtracted from the variable before scaling it
+	offset float64
+
+	// scale divides the shifted variable
+	scale float64
+}
+
+// fitPolynomial fits a polynomial of the degree to the points using least
+// squares. If there are not enough points for the degree, the degree is
+// reduced to the highest degree the points allow
+func fitPolynomial(x, y []float64, degree int) (polynomial, error) {
+	if len(x) == 0 {
+		return polynomial{}, fmt.Errorf("%w: no data points", ErrUnsuitableData)
+	}
+	degree = min(degree, len(x)-1)
+
+	// map the variable onto [-1, 1]
+	low, high := bounds(x)
+	p := polynomial{offset: (high + low) / 2, scale: (high - low) / 2}
+	if p.scale == 0 {
+		p.scale = 1
+		degree = 0
+	}
+
+	// now build the normal equations of the least squares problem as an
+	// augmented matrix
+	size := degree + 1
+	matrix := make([][]float64, size)
+	for row := range matrix {
+		matrix[row] = make([]float64, size+1)
+	}
+	for i := range x {
+		t := (x[i] - p.offset) / p.scale
+		powers := make([]float64, 2*size-1)
+		powers[0] = 1
+		for k := 1; k < len(powers); k++ {
+			powers[k] = powers[k-1] * t
+		}
+		for row := 0; row < size; row++ {
+			for column := 0; column < size; column++ {
+				matrix[row][column] += powers[row+column]
+			}
+			matrix[row][size] += powers[row] * y[i]
+		}
+	}
+
+	coefficients, err := solve(matrix)
+	if err != nil {
+		return polynomial{}, err
+	}
+	p.coefficients = coefficients
+	return p, nil
+}
+
+// at evaluates the polynomial using the horner scheme
+func (p polynomial) at(x float64) float64 {
+	t := (x - p.offset) / p.scale
+	var value float64
+	for k := len(p.coefficients) - 1; k >= 0; k-- {
+		value = value*t + p.coefficients[k]
+	}
+	return value
+}
+
+// expand returns the coefficients of the polynomial in the unscaled variable
+// ordered by ascending degree
+func (p polynomial) expand() []float64 {
+	// the scaled variable is a linear function a*x + b of the variable
+	a := 1 / p.scale
+	b := -p.offset / p.scale
+
+	expanded := []float64{0}
+	for k := len(p.coefficients) - 1; k >= 0; k-- {
+		// multiply the current result with a*x + b and add the coefficient
+		next := make([]float64, len(expanded)+1)
+		for degree, coefficient := range expanded {
+			next[degree] += coefficient * b
+			next[degree+1] += coefficient * a
+		}
+		next[0] += p.coefficients[k]
+		expanded = next
+	}
+	return expanded[:len(p.coefficients)]
+}
+
+// String returns the equation of the polynomial in the unscaled variable
+func (p polynomial) String() string {
+	return formatPolynomial(p.expand(), "x")
+}
+
+// formatPolynomial formats the coefficients as equation using the variable
+func formatPolynomial(coefficients []float64, variable string) string {
+	var equation strings.Builder
+	for degree, coefficient := range coefficients {
+		value := strconv.FormatFloat(math.Abs(coefficient), 'g', 8, 64)
+		switch {
+		case degree == 0 && coefficient < 0:
+			equation.WriteString("-" + value)
+		case degree == 0:
+			equation.WriteString(value)
+		case coefficient < 0:
+			equation.WriteString(" - " + value)
+		default:
+			equation.WriteString(" + " + value)
+		}
+		if degree > 0 {
+			equation.WriteString("·" + variable + superscript(degree))
+		}
+	}
+	return equation.String()
+}
+
+// superscript returns the exponent of a term as superscript. The first power
+// is not written
+func superscript(exponent int) string {
+	if exponent == 1 {
+		return ""
+	}
+	digits := []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+	var result strings.Builder
+	for _, digit := range strconv.Itoa(exponent) {
+		result.WriteRune(digits[digit-'0'])
+	}
+	return result.String()
+}
+
+// solve solves the linear system given as augmented matrix using gaussian
+// elimination with partial pivoting
+func solve(matrix [][]float64) ([]float64, error) {
+	size := len(matrix)
+	for column := 0; column < size; column++ {
+		pivot := column
+		for row := column + 1; row < size; row++ {
+			if math.Abs(matrix[row][column]) > math.Abs(matrix[pivot][column]) {
+				pivot = row
+			}
+		}
+		if math.Abs(matrix[pivot][column]) < 1e-12 {
+			return nil, errSingularSystem
+		}
+		matrix[column], matrix[pivot] = matrix[pivot], matrix[column]
+		for row := column + 1; row < size; row++ {
+			factor := matrix[row][column] / matrix[column][column]
+			for k := column; k <= size; k++ {
+				matrix[row][k] -= factor * matrix[column][k]
+			}
+		}
+	}
+
+	solution := make([]float64, size)
+	for row := size - 1; row >= 0; row-- {
+		value := matrix[row][size]
+		for k := row + 1; k < size; k++ {
+			value -= matrix[row][k] * solution[k]
+		}
+		solution[row] = value / matrix[row][row]
+	}
+	return solution, nil
+}
+
+// bounds returns the smallest and largest value of a non-empty slice
+func bounds(values []float64) (low, high float64) {
+	low, high = values[0], values[0]
+	for _, value := range values[1:] {
+		low = min(low, value)
+		high = max(high, value)
+	}
+	return low, high
+}
@@ -0,0 +1,220 @@
+package native
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// evaluate evaluates the polynomial given by the coefficients ordered by
+// ascending degree
+func evaluate(coefficients []float64, x float64) float64 {
+	var value float64
+	for k := len(coefficients) - 1; k >= 0; k-- {
+		value = value*x + coefficients[k]
+	}
+	return value
+}
+
+// closeTo checks if the values differ by at most the tolerance relative to
+// the larger value or by the tolerance if both values are small
+func closeTo(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestFitPolynomialRecoversCoefficients(t *testing.T) {
+	tests := []struct {
+		name         string
+		x            []float64
+		coefficients []float64
+		degree       int
+	}{
+		{
+			name:         "line",
+			x:            []float64{1, 2, 3, 4, 5},
+			coefficients: []float64{4, -1.5},
+			degree:       1,
+		},
+		{
+			name:         "cubic around the origin",
+			x:            []float64{-3, -2, -1, 0, 1, 2, 3},
+			coefficients: []float64{1, 0.5, -2, 0.25},
+			degree:       3,
+		},
+		{
+			name:         "parabola over years",
+			x:            []float64{2000, 2001, 2002, 2003, 2004, 2005, 2006, 2007, 2008, 2009, 2010},
+			coefficients: []float64{2, -3, 0.5},
+			degree:       2,
+		},
+		{
+			name:         "higher degree than the curve",
+			x:            []float64{2010, 2011, 2012, 2013, 2014, 2015},
+			coefficients: []float64{-7, 2, 0, 0},
+			degree:       3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			y := make([]float64, len(test.x))
+			for i, x := range test.x {
+				y[i] = evaluate(test.coefficients, x)
+			}
+			p, err := fitPolynomial(test.x, y, test.degree)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expanded := p.expand()
+			if len(expanded) != len(test.coefficients) {
+				t.Fatalf("expected coefficients %v, got %v", test.coefficients, expanded)
+			}
+			// expanding the polynomial cancels large terms for variables far
+			// from the origin, so the error of every coefficient is measured
+			// by its contribution to the values
+			_, largest := bounds(test.x)
+			scale := 0.0
+			for _, value := range y {
+				scale = math.Max(scale, math.Abs(value))
+			}
+			for k, coefficient := range test.coefficients {
+				contribution := math.Abs(expanded[k]-coefficient) * math.Pow(math.Abs(largest), float64(k))
+				if contribution > 1e-6*math.Max(1, scale) {
+					t.Errorf("expected coefficients %v, got %v", test.coefficients, expanded)
+					break
+				}
+			}
+
+			// the scaled polynomial and the expanded coefficients describe the
+			// same curve, also outside of the fitted points
+			for _, x := range append(test.x, test.x[len(test.x)-1]+5) {
+				if !closeTo(p.at(x), evaluate(test.coefficients, x), 1e-9) {
+					t.Errorf("expected %g at %g, got %g", evaluate(test.coefficients, x), x, p.at(x))
+				}
+				if !closeTo(p.at(x), evaluate(expanded, x), 1e-6) {
+					t.Errorf("expanded coefficients evaluate to %g at %g instead of %g", evaluate(expanded, x), x, p.at(x))
+				}
+			}
+		})
+	}
+}
+
+func TestFitPolynomialReducesDegree(t *testing.T) {
+	tests := []struct {
+		name   string
+		x, y   []float64
+		degree int
+		want   []float64
+	}{
+		{
+			name:   "fewer points than coefficients",
+			x:      []float64{2019, 2020, 2021},
+			y:      []float64{1, 3, 9},
+			degree: 5,
+			want:   []float64{1, 3, 9},
+		},
+		{
+			name:   "single point",
+			x:      []float64{2020},
+			y:      []float64{42},
+			degree: 3,
+			want:   []float64{42},
+		},
+		{
+			name:   "constant variable",
+			x:      []float64{2020, 2020, 2020},
+			y:      []float64{1, 2, 6},
+			degree: 2,
+			want:   []float64{3, 3, 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := fitPolynomial(test.x, test.y, test.degree)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if maxDegree := len(test.x) - 1; len(p.coefficients)-1 > maxDegree {
+				t.Errorf("expected degree of at most %d, got %d", maxDegree, len(p.coefficients)-1)
+			}
+			for i, x := range test.x {
+				if !closeTo(p.at(x), test.want[i], 1e-9) {
+					t.Errorf("expected %g at %g, got %g", test.want[i], x, p.at(x))
+				}
+				if value := evaluate(p.expand(), x); !closeTo(value, test.want[i], 1e-6) {
+					t.Errorf("expanded coefficients evaluate to %g at %g instead of %g", value, x, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFitPolynomialRejectsUnsuitablePoints(t *testing.T) {
+	_, err := fitPolynomial(nil, nil, 1)
+	if !errors.Is(err, ErrUnsuitableData) {
+		t.Errorf("expected ErrUnsuitableData without points, got %v", err)
+	}
+
+	// three points at two distinct positions do not determine a parabola
+	_, err = fitPolynomial([]float64{2020, 2020, 2021}, []float64{1, 2, 3}, 2)
+	if !errors.Is(err, errSingularSystem) || !errors.Is(err, ErrUnsuitableData) {
+		t.Errorf("expected singular system, got %v", err)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  [][]float64
+		want    []float64
+		wantErr error
+	}{
+		{
+			name:   "requires pivoting",
+			matrix: [][]float64{{0, 2, 4}, {3, 1, 5}},
+			want:   []float64{1, 2},
+		},
+		{
+			name:   "three equations",
+			matrix: [][]float64{{2, 1, -1, 8}, {-3, -1, 2, -11}, {-2, 1, 2, -3}},
+			want:   []float64{2, 3, -1},
+		},
+		{
+			name:    "linearly dependent rows",
+			matrix:  [][]float64{{1, 2, 3}, {2, 4, 6}},
+			wantErr: errSingularSystem,
+		},
+		{
+			name:    "zero matrix",
+			matrix:  [][]float64{{0, 0, 1}, {0, 0, 1}},
+			wantErr: errSingularSystem,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			solution, err := solve(test.matrix)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+			for i, value := range test.want {
+				if !closeTo(solution[i], value, 1e-12) {
+					t.Errorf("expected solution %v, got %v", test.want, solution)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPolynomialString(t *testing.T) {
+	p, err := fitPolynomial([]float64{-1, 0, 1, 2}, []float64{6, 1, -2, -3}, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if equation, want := p.String(), "1 - 4·x + 1·x²"; equation != want {
+		t.Errorf("expected '%s', got '%s'", want, equation)
+	}
+}
@@ -0,0 +1,193 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// regressionParameters contains the parameters shared by the regressions
+type regressionParameters struct {
+	// Size contains the number of years forecasted after the last year with
+	// usage data
+	Size int `json:"size"`
+
+	// Degree contains the degree of the fitted polynomial. It is only used
+	// by the polynomial regression
+	Degree int `json:"degree"`
+
+	// GroupBy selects if the data series are built per municipal or per usage
+	// type
+	GroupBy string `json:"groupBy"`
+}
+
+// curve is a function fitted to the usage data of a data series
+type curve interface {
+	// at evaluates the curve for the year
+	at(x float64) float64
+
+	// String returns the equation of the curve
+	String() string
+}
+
+// fitFunction fits a curve to the yearly usages of a data series
+type fitFunction func(years, usages []float64, parameters regressionParameters) (curve, error)
+
+// series contains the yearly usages of a single data series
+type series struct {
+	label  string
+	years  []int
+	usages []float64
+}
+
+// regression creates a forecast using the fit function. The usage data is
+// summed up per year and data series before fitting a curve to every data
+// series. The curve is then used to forecast the usages of the years after the
+// last year with usage data
+func regression(fit fitFunction) Forecast {
+	return func(ctx context.Context, usageDataPoints []types.UsageDataPoint, rawParameters []byte) (types.ForecastResult, error) {
+		var parameters regressionParameters
+		if err := json.Unmarshal(rawParameters, &parameters); err != nil {
+			return types.ForecastResult{}, fmt.Errorf("unable to parse parameters: %w", err)
+		}
+
+		result := types.ForecastResult{
+			Meta: types.ForecastMetadata{
+				Curves:        make(map[string]string),
+				RScores:       make(map[string]float64),
+				RealDataUntil: make(map[string]float64),
+			},
+			Data: []types.ForecastDataPoint{},
+		}
+
+		for _, dataSeries := range yearlySeries(usageDataPoints, parameters.GroupBy) {
+			if err := ctx.Err(); err != nil {
+				return types.ForecastResult{}, err
+			}
+
+			years := make([]float64, len(dataSeries.years))
+			for i, year := range dataSeries.years {
+				years[i] = float64(year)
+				result.Data = append(result.Data, types.ForecastDataPoint{
+					Label: dataSeries.label,
+					X:     float64(year),
+					Y:     dataSeries.usages[i],
+				})
+			}
+
+			fitted, err := fit(years, dataSeries.usages, parameters)
+			if err != nil {
+				return types.ForecastResult{}, fmt.Errorf("unable to fit curve for '%s': %w", dataSeries.label, err)
+			}
+
+			reference := make([]float64, len(years))
+			for i, year := range years {
+				reference[i] = fitted.at(year)
+			}
+			lastYear := dataSeries.years[len(dataSeries.years)-1]
+			result.Meta.Curves[dataSeries.label] = fitted.String()
+			result.Meta.RScores[dataSeries.label] = rSquared(dataSeries.usages, reference)
+			result.Meta.RealDataUntil[dataSeries.label] = float64(lastYear)
+
+			for year := lastYear + 1; year <= lastYear+parameters.Size; year++ {
+				value := fitted.at(float64(year))
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					return types.ForecastResult{}, fmt.Errorf("%w: curve for '%s' is not defined for %d", ErrUnsuitableData, dataSeries.label, year)
+				}
+				result.Data = append(result.Data, types.ForecastDataPoint{
+					Label: dataSeries.label,
+					X:     float64(year),
+					Y:     value,
+				})
+			}
+		}
+		return result, nil
+	}
+}
+
+// yearlySeries groups the usage data into data series and sums up the usages
+// of every year. The data series are sorted by their label and the usages by
+// their year
+func yearlySeries(usageDataPoints []types.UsageDataPoint, groupBy string) []series {
+	sums := make(map[string]map[int]float64)
+	for _, dataPoint := range usageDataPoints {
+		if !dataPoint.Date.Valid {
+			continue
+		}
+		label, ok := seriesLabel(dataPoint, groupBy)
+		if !ok {
+			continue
+		}
+		if sums[label] == nil {
+			sums[label] = make(map[int]float64)
+		}
+		sums[label][dataPoint.Date.Time.UTC().Year()] += dataPoint.Amount
+	}
+
+	labels := make([]string, 0, len(sums))
+	for label := range sums {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+
+	allSeries := make([]series, 0, len(labels))
+	for _, label := range labels {
+		dataSeries := series{label: label}
+		for year := range sums[label] {
+			dataSeries.years = append(dataSeries.years, year)
+		}
+		slices.Sort(dataSeries.years)
+		for _, year := range dataSeries.years {
+			dataSeries.usages = append(dataSeries.usages, sums[label][year])
+		}
+		allSeries = append(allSeries, dataSeries)
+	}
+	return allSeries
+}
+
+// seriesLabel returns the label of the data series the usage data point
+// belongs to. Usages without a usage type are not part of any data series if
+// the data is grouped by the usage type
+func seriesLabel(dataPoint types.UsageDataPoint, groupBy string) (string, bool) {
+	if groupBy != "usageType" {
+		return dataPoint.Municipal, true
+	}
+	if !dataPoint.UsageType.Valid {
+		return "", false
+	}
+	value, _ := dataPoint.UsageType.Value()
+	label, ok := value.(string)
+	return label, ok
+}
+
+// rSquared calculates the coefficient of determination of the predicted
+// values. If the actual values are constant, a perfect prediction results in
+// 1 and every other prediction in 0
+func rSquared(actual, predicted []float64) float64 {
+	var mean float64
+	for _, value := range actual {
+		mean += value
+	}
+	mean /= float64(len(actual))
+
+	var residualSum, totalSum float64
+	for i, value := range actual {
+		residualSum += math.Pow(value-predicted[i], 2)
+		totalSum += math.Pow(value-mean, 2)
+	}
+	if totalSum == 0 {
+		if residualSum == 0 {
+			return 1
+		}
+		return 0
+	}
+	score := 1 - residualSum/totalSum
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		return 0
+	}
+	return score
+}
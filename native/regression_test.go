@@ -0,0 +1,203 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// yearlyUsageData creates a usage data point in January and July of every
+// year. Both data points contain half of the usage of the year
+func yearlyUsageData(municipal string, firstYear int, usages []float64) []types.UsageDataPoint {
+	var usageDataPoints []types.UsageDataPoint
+	for i, usage := range usages {
+		for _, month := range []time.Month{time.January, time.July} {
+			usageDataPoints = append(usageDataPoints, types.UsageDataPoint{
+				Municipal: municipal,
+				UsageType: pgtype.UUID{Bytes: [16]byte{0x01}, Valid: true},
+				Date:      pgtype.Timestamptz{Time: time.Date(firstYear+i, month, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+				Amount:    usage / 2,
+			})
+		}
+	}
+	return usageDataPoints
+}
+
+// sortedKeys returns the sorted keys of the map
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestRegressionResultShape(t *testing.T) {
+	const size = 5
+	usages := map[string][]float64{
+		"031510000000": {100, 104, 107, 112, 115, 119, 124},
+		"031520000000": {80, 78, 77, 73, 70, 69, 65},
+	}
+	var usageDataPoints []types.UsageDataPoint
+	for municipal, series := range usages {
+		usageDataPoints = append(usageDataPoints, yearlyUsageData(municipal, 2014, series)...)
+	}
+	labels := sortedKeys(usages)
+
+	for _, implementation := range []Implementation{linear, exponential, logarithmic} {
+		t.Run(implementation.Identifier, func(t *testing.T) {
+			parameters := []byte(`{"size": 5, "degree": 2, "groupBy": "municipal"}`)
+			result, err := implementation.Forecast(context.Background(), usageDataPoints, parameters)
+			if err != nil {
+				t.Fatalf("unable to forecast: %v", err)
+			}
+
+			for name, metadata := range map[string][]string{
+				"curves":        sortedKeys(result.Meta.Curves),
+				"rScores":       sortedKeys(result.Meta.RScores),
+				"realDataUntil": sortedKeys(result.Meta.RealDataUntil),
+			} {
+				if !slices.Equal(metadata, labels) {
+					t.Errorf("expected %s for %v, got %v", name, labels, metadata)
+				}
+			}
+
+			for _, label := range labels {
+				if until := result.Meta.RealDataUntil[label]; until != 2020 {
+					t.Errorf("expected real data of '%s' until 2020, got %g", label, until)
+				}
+				if score := result.Meta.RScores[label]; score < 0.9 || score > 1 {
+					t.Errorf("expected r² score of '%s' close to 1, got %g", label, score)
+				}
+
+				// the usages are returned per year, followed by one forecasted
+				// value for every year after the real data
+				var years []float64
+				for _, dataPoint := range result.Data {
+					if dataPoint.Label != label {
+						continue
+					}
+					if dataPoint.Uncertainty != nil || dataPoint.Date != nil {
+						t.Errorf("unexpected uncertainty or date in %+v", dataPoint)
+					}
+					if dataPoint.X <= 2020 && dataPoint.Y != usages[label][int(dataPoint.X)-2014] {
+						t.Errorf("expected usage %g in %g, got %g", usages[label][int(dataPoint.X)-2014], dataPoint.X, dataPoint.Y)
+					}
+					years = append(years, dataPoint.X)
+				}
+				var want []float64
+				for year := 2014; year <= 2020+size; year++ {
+					want = append(want, float64(year))
+				}
+				if !slices.Equal(years, want) {
+					t.Errorf("expected years %v for '%s', got %v", want, label, years)
+				}
+			}
+
+			// the encoded result uses the fields written by the scripts
+			encoded, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("unable to encode result: %v", err)
+			}
+			var document struct {
+				Meta map[string]json.RawMessage   `json:"meta"`
+				Data []map[string]json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(encoded, &document); err != nil {
+				t.Fatalf("unable to decode result: %v", err)
+			}
+			if fields := sortedKeys(document.Meta); !slices.Equal(fields, []string{"curves", "rScores", "realDataUntil"}) {
+				t.Errorf("unexpected metadata fields %v", fields)
+			}
+			for _, dataPoint := range document.Data {
+				if fields := sortedKeys(dataPoint); !slices.Equal(fields, []string{"label", "x", "y"}) {
+					t.Errorf("unexpected data point fields %v", fields)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRegressionNonPositiveValues(t *testing.T) {
+	tests := []struct {
+		name           string
+		implementation Implementation
+		firstYear      int
+		usages         []float64
+		wantUnsuitable bool
+	}{
+		{
+			name:           "logarithmic with non-positive years",
+			implementation: logarithmic,
+			firstYear:      -1,
+			usages:         []float64{10, 11, 12},
+			wantUnsuitable: true,
+		},
+		{
+			name:           "logarithmic with non-positive usages",
+			implementation: logarithmic,
+			firstYear:      2018,
+			usages:         []float64{0, -5, -10},
+		},
+		{
+			name:           "exponential with non-positive usages",
+			implementation: exponential,
+			firstYear:      2018,
+			usages:         []float64{0, -5, -10, -20},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			usageDataPoints := yearlyUsageData("031510000000", test.firstYear, test.usages)
+			parameters := []byte(`{"size": 3, "degree": 2, "groupBy": "municipal"}`)
+			result, err := test.implementation.Forecast(context.Background(), usageDataPoints, parameters)
+			if test.wantUnsuitable {
+				if !errors.Is(err, ErrUnsuitableData) {
+					t.Errorf("expected ErrUnsuitableData, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, dataPoint := range result.Data {
+				if math.IsNaN(dataPoint.Y) || math.IsInf(dataPoint.Y, 0) {
+					t.Errorf("invalid value %g in %g", dataPoint.Y, dataPoint.X)
+				}
+			}
+		})
+	}
+}
+
+func TestRSquared(t *testing.T) {
+	tests := []struct {
+		name              string
+		actual, predicted []float64
+		want              float64
+	}{
+		{name: "perfect prediction", actual: []float64{1, 2, 3}, predicted: []float64{1, 2, 3}, want: 1},
+		{name: "mean as prediction", actual: []float64{1, 2, 3}, predicted: []float64{2, 2, 2}, want: 0},
+		{name: "worse than the mean", actual: []float64{1, 2, 3}, predicted: []float64{3, 2, 1}, want: -3},
+		{name: "constant values predicted", actual: []float64{5, 5}, predicted: []float64{5, 5}, want: 1},
+		{name: "constant values missed", actual: []float64{5, 5}, predicted: []float64{4, 6}, want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if score := rSquared(test.actual, test.predicted); !closeTo(score, test.want, 1e-12) {
+				t.Errorf("expected %g, got %g", test.want, score)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package native
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// linear fits a straight line to the yearly usages
+var linear = Implementation{
+	Identifier: "linear",
+	Metadata: types.AlgorithmMetadata{
+		DisplayName: "Linear Fit",
+		Description: "An algorithm that implements an linear fit",
+		Parameters: map[string]types.Parameter{
+			"size":    sizeParameter,
+			"groupBy": groupByParameter,
+		},
+		UseBuckets: true,
+		BucketSize: "1 year",
+	},
+	Forecast: regression(func(years, usages []float64, _ regressionParameters) (curve, error) {
+		return fitPolynomial(years, usages, 1)
+	}),
+}
+
+// exponential fits a polynomial of a variable degree to the yearly usages
+var exponential = Implementation{
+	Identifier: "exponential",
+	Metadata: types.AlgorithmMetadata{
+		DisplayName: "Exponential Curve",
+		Description: "An algorithm that implements an exponential fit to a variable degree",
+		Parameters: map[string]types.Parameter{
+			"size": sizeParameter,
+			"degree": {
+				Description:  "The degree of the polynomial equation that should be used to fit the function to",
+				DefaultValue: 3,
+				Type:         "int",
+				Min:          &minimalDegree,
+				Max:          &maximalDegree,
+			},
+			"groupBy": groupByParameter,
+		},
+		UseBuckets: true,
+		BucketSize: "1 year",
+	},
+	Forecast: regression(func(years, usages []float64, parameters regressionParameters) (curve, error) {
+		return fitPolynomial(years, usages, parameters.Degree)
+	}),
+}
+
+// minimalDegree and maximalDegree limit the degree of the polynomial fitted
+// by the exponential regression. Higher degrees overfit the few yearly usages
+// and render the least squares problem ill-conditioned
+var (
+	minimalDegree = 1
+	maximalDegree = 10
+)
+
+// logarithmic fits a linear function of the logarithm of the year to the
+// yearly usages
+var logarithmic = Implementation{
+	Identifier: "logarithmic",
+	Metadata: types.AlgorithmMetadata{
+		DisplayName: "Logarithmic Fit",
+		Description: "An algorithm that implements an logarithmic fit",
+		Parameters: map[string]types.Parameter{
+			"size":    sizeParameter,
+			"groupBy": groupByParameter,
+		},
+		UseBuckets: true,
+		BucketSize: "1 year",
+	},
+	Forecast: regression(func(years, usages []float64, _ regressionParameters) (curve, error) {
+		logarithms := make([]float64, len(years))
+		for i, year := range years {
+			if year <= 0 {
+				return nil, fmt.Errorf("%w: logarithmic fit requires positive years", ErrUnsuitableData)
+			}
+			logarithms[i] = math.Log(year)
+		}
+		p, err := fitPolynomial(logarithms, usages, 1)
+		if err != nil {
+			return nil, err
+		}
+		return logarithmicCurve{p}, nil
+	}),
+}
+
+// logarithmicCurve is a polynomial of the natural logarithm of the variable
+type logarithmicCurve struct {
+	polynomial polynomial
+}
+
+func (c logarithmicCurve) at(x float64) float64 {
+	return c.polynomial.at(math.Log(x))
+}
+
+func (c logarithmicCurve) String() string {
+	return formatPolynomial(c.polynomial.expand(), "ln(x)")
+}
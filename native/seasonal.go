@@ -0,0 +1,369 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// seasonalParameterDefinitions returns the descriptions of the parameters
+// shared by the seasonal models
+func seasonalParameterDefinitions() map[string]types.Parameter {
+	return map[string]types.Parameter{
+		"size": {
+			Description:  "The amount of months that shall be predicted, after the source data is available",
+			DefaultValue: 24,
+			Type:         "int",
+			Min:          &minimalSize,
+		},
+		"seasonLength": {
+			Description:  "The amount of months after which the seasonal pattern repeats",
+			DefaultValue: 12,
+			Type:         "int",
+			Min:          &minimalSeasonLength,
+			Max:          &maximalSeasonLength,
+		},
+		"intervalWidth": {
+			Description:  "The probability covered by the uncertainty intervals around the forecasted data. The value needs to be between 0 and 1",
+			DefaultValue: 0.8,
+			Type:         "float",
+			Min:          &minimalIntervalWidth,
+			Max:          &maximalIntervalWidth,
+			ExclusiveMin: true,
+			ExclusiveMax: true,
+		},
+		"groupBy": groupByParameter,
+	}
+}
+
+// the limits of the parameters shared by the seasonal models
+var (
+	minimalSeasonLength  = 2
+	maximalSeasonLength  = 24
+	minimalIntervalWidth = 0
+	maximalIntervalWidth = 1
+)
+
+// seasonalParameters contains the parameters shared by the seasonal models
+type seasonalParameters struct {
+	// Size contains the number of months forecasted after the last month with
+	// usage data
+	Size int `json:"size"`
+
+	// SeasonLength contains the number of months after which the seasonal
+	// pattern repeats
+	SeasonLength int `json:"seasonLength"`
+
+	// Seasonality selects if the seasonal component is added to or multiplied
+	// with the level of the Holt-Winters model
+	Seasonality string `json:"seasonality"`
+
+	// IntervalWidth contains the probability covered by the prediction
+	// intervals
+	IntervalWidth float64 `json:"intervalWidth"`
+
+	// GroupBy selects if the data series are built per municipal or per usage
+	// type
+	GroupBy string `json:"groupBy"`
+}
+
+// seasonalFit contains a model fitted to the monthly usages of a data series
+type seasonalFit struct {
+	// fitted contains the one-step-ahead predictions of the model for the
+	// months with usage data. The predictions for months used to initialize
+	// the model are NaN
+	fitted []float64
+
+	// forecast contains the forecasted usages
+	forecast []float64
+
+	// standardErrors contains the standard error of every forecasted usage
+	standardErrors []float64
+
+	// description describes the fitted model
+	description string
+}
+
+// seasonalFunction fits a seasonal model to the monthly usages of a data
+// series and forecasts the usages of the following months
+type seasonalFunction func(ctx context.Context, usages []float64, parameters seasonalParameters) (seasonalFit, error)
+
+// monthlySeries contains the monthly usages of a single data series
+type monthlySeries struct {
+	label  string
+	start  time.Time
+	usages []float64
+}
+
+// seasonal creates a forecast using the seasonal model. The usage data is
+// summed up per month and data series and the model is fitted to every data
+// series separately. The forecasted values contain prediction intervals
+// covering the requested interval width
+func seasonal(fit seasonalFunction) Forecast {
+	return func(ctx context.Context, usageDataPoints []types.UsageDataPoint, rawParameters []byte) (types.ForecastResult, error) {
+		var parameters seasonalParameters
+		if err := json.Unmarshal(rawParameters, &parameters); err != nil {
+			return types.ForecastResult{}, fmt.Errorf("unable to parse parameters: %w", err)
+		}
+		if parameters.IntervalWidth <= 0 || parameters.IntervalWidth >= 1 {
+			return types.ForecastResult{}, fmt.Errorf("%w: interval width needs to be between 0 and 1", ErrUnsuitableData)
+		}
+		quantile := math.Sqrt2 * math.Erfinv(parameters.IntervalWidth)
+
+		result := types.ForecastResult{
+			Meta: types.ForecastMetadata{
+				RScores:       make(map[string]float64),
+				RealDataUntil: make(map[string]float64),
+				Models:        make(map[string]string),
+			},
+			Data: []types.ForecastDataPoint{},
+		}
+
+		for _, dataSeries := range monthlySeriesOf(usageDataPoints, parameters.GroupBy) {
+			if err := ctx.Err(); err != nil {
+				return types.ForecastResult{}, err
+			}
+			if len(dataSeries.usages) < 2*parameters.SeasonLength {
+				return types.ForecastResult{}, fmt.Errorf("%w: data series '%s' needs to contain at least two seasons (%d months) of usage data", ErrUnsuitableData, dataSeries.label, 2*parameters.SeasonLength)
+			}
+
+			for i, usage := range dataSeries.usages {
+				result.Data = append(result.Data, monthlyDataPoint(dataSeries.label, dataSeries.start, i, usage))
+			}
+
+			model, err := fit(ctx, dataSeries.usages, parameters)
+			if err != nil {
+				return types.ForecastResult{}, fmt.Errorf("unable to fit model for '%s': %w", dataSeries.label, err)
+			}
+
+			// the r² score is calculated on the months predicted by the model
+			var actual, predicted []float64
+			for i, value := range model.fitted {
+				if math.IsNaN(value) {
+					continue
+				}
+				actual = append(actual, dataSeries.usages[i])
+				predicted = append(predicted, value)
+			}
+			if len(actual) > 0 {
+				result.Meta.RScores[dataSeries.label] = rSquared(actual, predicted)
+			}
+			last := len(dataSeries.usages) - 1
+			result.Meta.RealDataUntil[dataSeries.label] = monthlyDataPoint("", dataSeries.start, last, 0).X
+			result.Meta.Models[dataSeries.label] = model.description
+
+			for step, value := range model.forecast {
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					return types.ForecastResult{}, fmt.Errorf("%w: model for '%s' diverged", ErrUnsuitableData, dataSeries.label)
+				}
+				dataPoint := monthlyDataPoint(dataSeries.label, dataSeries.start, last+1+step, value)
+				margin := quantile * model.standardErrors[step]
+				dataPoint.Uncertainty = &[2]float64{value - margin, value + margin}
+				result.Data = append(result.Data, dataPoint)
+			}
+		}
+		return result, nil
+	}
+}
+
+// monthlyDataPoint creates the data point for the month with the offset to
+// the start of the data series
+func monthlyDataPoint(label string, start time.Time, offset int, value float64) types.ForecastDataPoint {
+	date := start.AddDate(0, offset, 0)
+	return types.ForecastDataPoint{
+		Label: label,
+		X:     float64(date.Year()) + float64(date.Month()-1)/12,
+		Y:     value,
+		Date:  &date,
+	}
+}
+
+// monthlySeriesOf groups the usage data into data series and sums up the usages
+// of every month. Months without usage data between the first and last month
+// of a data series are interpolated linearly. The data series are sorted by
+// their label
+func monthlySeriesOf(usageDataPoints []types.UsageDataPoint, groupBy string) []monthlySeries {
+	sums := make(map[string]map[time.Time]float64)
+	for _, dataPoint := range usageDataPoints {
+		if !dataPoint.Date.Valid {
+			continue
+		}
+		label, ok := seriesLabel(dataPoint, groupBy)
+		if !ok {
+			continue
+		}
+		if sums[label] == nil {
+			sums[label] = make(map[time.Time]float64)
+		}
+		date := dataPoint.Date.Time.UTC()
+		month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		sums[label][month] += dataPoint.Amount
+	}
+
+	labels := make([]string, 0, len(sums))
+	for label := range sums {
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+
+	allSeries := make([]monthlySeries, 0, len(labels))
+	for _, label := range labels {
+		months := make([]time.Time, 0, len(sums[label]))
+		for month := range sums[label] {
+			months = append(months, month)
+		}
+		slices.SortFunc(months, func(a, b time.Time) int {
+			return a.Compare(b)
+		})
+
+		dataSeries := monthlySeries{label: label, start: months[0]}
+		end := months[len(months)-1]
+		lastKnown := -1
+		for month := dataSeries.start; !month.After(end); month = month.AddDate(0, 1, 0) {
+			usage, known := sums[label][month]
+			if !known {
+				dataSeries.usages = append(dataSeries.usages, math.NaN())
+				continue
+			}
+			// now interpolate the months since the last known usage
+			index := len(dataSeries.usages)
+			for gap := lastKnown + 1; gap < index; gap++ {
+				share := float64(gap-lastKnown) / float64(index-lastKnown)
+				dataSeries.usages[gap] = dataSeries.usages[lastKnown] + share*(usage-dataSeries.usages[lastKnown])
+			}
+			dataSeries.usages = append(dataSeries.usages, usage)
+			lastKnown = index
+		}
+		allSeries = append(allSeries, dataSeries)
+	}
+	return allSeries
+}
+
+// minimize searches the parameters minimizing the objective using the
+// Nelder-Mead method. The parameters are clamped to the bounds before
+// evaluating the objective
+func minimize(ctx context.Context, objective func([]float64) float64, initial, lower, upper []float64) ([]float64, error) {
+	const (
+		iterations = 500
+		tolerance  = 1e-10
+	)
+	dimensions := len(initial)
+	clamp := func(point []float64) []float64 {
+		clamped := make([]float64, dimensions)
+		for i, value := range point {
+			clamped[i] = math.Min(math.Max(value, lower[i]), upper[i])
+		}
+		return clamped
+	}
+	evaluate := func(point []float64) float64 {
+		value := objective(point)
+		if math.IsNaN(value) {
+			return math.Inf(1)
+		}
+		return value
+	}
+
+	// the initial simplex consists of the initial point and a point moved
+	// along every dimension
+	simplex := make([][]float64, dimensions+1)
+	values := make([]float64, dimensions+1)
+	simplex[0] = clamp(initial)
+	for i := 0; i < dimensions; i++ {
+		point := slices.Clone(simplex[0])
+		step := 0.1 * (upper[i] - lower[i])
+		if point[i]+step > upper[i] {
+			step = -step
+		}
+		point[i] += step
+		simplex[i+1] = clamp(point)
+	}
+	for i, point := range simplex {
+		values[i] = evaluate(point)
+	}
+
+	for iteration := 0; iteration < iterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// order the simplex by the value of the objective
+		order := make([]int, len(simplex))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int {
+			switch {
+			case values[a] < values[b]:
+				return -1
+			case values[a] > values[b]:
+				return 1
+			}
+			return 0
+		})
+		sortedSimplex := make([][]float64, len(simplex))
+		sortedValues := make([]float64, len(values))
+		for i, index := range order {
+			sortedSimplex[i] = simplex[index]
+			sortedValues[i] = values[index]
+		}
+		simplex, values = sortedSimplex, sortedValues
+
+		if math.Abs(values[dimensions]-values[0]) <= tolerance*(math.Abs(values[0])+tolerance) {
+			break
+		}
+
+		centroid := make([]float64, dimensions)
+		for _, point := range simplex[:dimensions] {
+			for i, value := range point {
+				centroid[i] += value / float64(dimensions)
+			}
+		}
+		along := func(factor float64) []float64 {
+			point := make([]float64, dimensions)
+			for i := range point {
+				point[i] = centroid[i] + factor*(simplex[dimensions][i]-centroid[i])
+			}
+			return clamp(point)
+		}
+
+		reflected := along(-1)
+		reflectedValue := evaluate(reflected)
+		switch {
+		case reflectedValue < values[0]:
+			expanded := along(-2)
+			if expandedValue := evaluate(expanded); expandedValue < reflectedValue {
+				simplex[dimensions], values[dimensions] = expanded, expandedValue
+			} else {
+				simplex[dimensions], values[dimensions] = reflected, reflectedValue
+			}
+		case reflectedValue < values[dimensions-1]:
+			simplex[dimensions], values[dimensions] = reflected, reflectedValue
+		default:
+			contracted := along(0.5)
+			if contractedValue := evaluate(contracted); contractedValue < values[dimensions] {
+				simplex[dimensions], values[dimensions] = contracted, contractedValue
+				continue
+			}
+			// shrink the simplex towards the best point
+			for i := 1; i <= dimensions; i++ {
+				for k := range simplex[i] {
+					simplex[i][k] = simplex[0][k] + 0.5*(simplex[i][k]-simplex[0][k])
+				}
+				values[i] = evaluate(simplex[i])
+			}
+		}
+	}
+
+	best := 0
+	for i, value := range values {
+		if value < values[best] {
+			best = i
+		}
+	}
+	return simplex[best], nil
+}
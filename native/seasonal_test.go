@@ -0,0 +1,254 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// testStart contains the first month of the data series used by the tests
+var testStart = time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// monthlyUsageData creates a usage data point for every month of the data
+// series starting at testStart. Months with a NaN usage are left out
+func monthlyUsageData(municipal string, usages []float64) []types.UsageDataPoint {
+	usageDataPoints := make([]types.UsageDataPoint, 0, len(usages))
+	for month, usage := range usages {
+		if math.IsNaN(usage) {
+			continue
+		}
+		usageDataPoints = append(usageDataPoints, types.UsageDataPoint{
+			Municipal: municipal,
+			UsageType: pgtype.UUID{Bytes: [16]byte{0x01}, Valid: true},
+			Date:      pgtype.Timestamptz{Time: testStart.AddDate(0, month, 0), Valid: true},
+			Amount:    usage,
+		})
+	}
+	return usageDataPoints
+}
+
+// seasonalSeries generates the monthly usages of a linear trend with a
+// sinusoidal seasonal pattern with a period of twelve months. The pattern is
+// either added to the trend or scales it
+func seasonalSeries(months int, multiplicative bool) []float64 {
+	usages := make([]float64, months)
+	for t := range usages {
+		trend := 100 + 0.5*float64(t)
+		season := math.Sin(2 * math.Pi * float64(t) / 12)
+		if multiplicative {
+			usages[t] = trend * (1 + 0.2*season)
+		} else {
+			usages[t] = trend + 10*season
+		}
+	}
+	return usages
+}
+
+// runSeasonal forecasts the usages using the seasonal model with the
+// parameters and returns the result
+func runSeasonal(t *testing.T, fit seasonalFunction, usages []float64, parameters seasonalParameters) (types.ForecastResult, error) {
+	t.Helper()
+	if parameters.SeasonLength == 0 {
+		parameters.SeasonLength = 12
+	}
+	if parameters.IntervalWidth == 0 {
+		parameters.IntervalWidth = 0.8
+	}
+	parameters.GroupBy = "municipal"
+	raw, err := json.Marshal(parameters)
+	if err != nil {
+		t.Fatalf("unable to encode parameters: %v", err)
+	}
+	return seasonal(fit)(context.Background(), monthlyUsageData("031510000000", usages), raw)
+}
+
+// forecastedPoints returns the data points of the result which have been
+// forecasted
+func forecastedPoints(result types.ForecastResult) []types.ForecastDataPoint {
+	var forecasted []types.ForecastDataPoint
+	for _, dataPoint := range result.Data {
+		if dataPoint.Uncertainty != nil {
+			forecasted = append(forecasted, dataPoint)
+		}
+	}
+	return forecasted
+}
+
+// checkSeasonalForecast fits the model to the first months of the generated
+// series and checks that the forecast follows the remaining months within the
+// relative tolerance
+func checkSeasonalForecast(t *testing.T, fit seasonalFunction, multiplicative bool, parameters seasonalParameters, tolerance float64) {
+	t.Helper()
+	const known, size = 60, 12
+	usages := seasonalSeries(known+size, multiplicative)
+	parameters.Size = size
+
+	result, err := runSeasonal(t, fit, usages[:known], parameters)
+	if err != nil {
+		t.Fatalf("unable to forecast: %v", err)
+	}
+	forecasted := forecastedPoints(result)
+	if len(forecasted) != size {
+		t.Fatalf("expected %d forecasted months, got %d", size, len(forecasted))
+	}
+	for step, dataPoint := range forecasted {
+		expected := usages[known+step]
+		if math.Abs(dataPoint.Y-expected) > tolerance*expected {
+			t.Errorf("month %d: expected %.2f, got %.2f", step+1, expected, dataPoint.Y)
+		}
+	}
+}
+
+// checkIntervals checks that the intervals contain the forecasted usages and
+// do not get narrower with the horizon. For multiplicative seasonality, the
+// intervals scale with the forecast and their width is therefore compared
+// relative to the forecast
+func checkIntervals(t *testing.T, fit seasonalFunction, parameters seasonalParameters) {
+	t.Helper()
+	parameters.Size = 24
+	multiplicative := parameters.Seasonality == "multiplicative"
+	usages := seasonalSeries(60, multiplicative)
+	// the noise prevents a perfect fit, which would result in empty intervals
+	for i := range usages {
+		usages[i] += 3 * math.Sin(float64(i*i))
+	}
+
+	result, err := runSeasonal(t, fit, usages, parameters)
+	if err != nil {
+		t.Fatalf("unable to forecast: %v", err)
+	}
+	previousWidth := 0.0
+	for step, dataPoint := range forecastedPoints(result) {
+		lower, upper := dataPoint.Uncertainty[0], dataPoint.Uncertainty[1]
+		if dataPoint.Y < lower || dataPoint.Y > upper {
+			t.Errorf("month %d: forecast %.2f outside of interval [%.2f, %.2f]", step+1, dataPoint.Y, lower, upper)
+		}
+		width := upper - lower
+		if multiplicative {
+			width /= math.Abs(dataPoint.Y)
+		}
+		if width <= 0 || width < previousWidth-1e-9 {
+			t.Errorf("month %d: interval width %.4f after %.4f", step+1, width, previousWidth)
+		}
+		previousWidth = width
+	}
+}
+
+func TestSeasonalRejectsShortSeries(t *testing.T) {
+	for name, fit := range map[string]seasonalFunction{"holt-winters": fitHoltWinters, "arima": fitArima} {
+		t.Run(name, func(t *testing.T) {
+			_, err := runSeasonal(t, fit, seasonalSeries(23, false), seasonalParameters{Size: 12, SeasonLength: 12})
+			if !errors.Is(err, ErrUnsuitableData) {
+				t.Errorf("expected ErrUnsuitableData for 23 months, got %v", err)
+			}
+			_, err = runSeasonal(t, fit, seasonalSeries(8, false), seasonalParameters{Size: 4, SeasonLength: 4})
+			if err != nil {
+				t.Errorf("unexpected error for two seasons: %v", err)
+			}
+		})
+	}
+}
+
+func TestMonthlySeriesOfInterpolatesGaps(t *testing.T) {
+	usageDataPoints := monthlyUsageData("031510000000", []float64{10, math.NaN(), math.NaN(), 40, 50, math.NaN(), 30})
+	// a second usage in the same month is added to the month
+	usageDataPoints = append(usageDataPoints, types.UsageDataPoint{
+		Municipal: "031510000000",
+		Date:      pgtype.Timestamptz{Time: testStart.AddDate(0, 4, 14), Valid: true},
+		Amount:    10,
+	})
+	usageDataPoints = append(usageDataPoints, monthlyUsageData("031520000000", []float64{math.NaN(), 5})...)
+
+	allSeries := monthlySeriesOf(usageDataPoints, "municipal")
+	if len(allSeries) != 2 {
+		t.Fatalf("expected 2 data series, got %d", len(allSeries))
+	}
+
+	tests := []struct {
+		label  string
+		start  time.Time
+		usages []float64
+	}{
+		{label: "031510000000", start: testStart, usages: []float64{10, 20, 30, 40, 60, 45, 30}},
+		{label: "031520000000", start: testStart.AddDate(0, 1, 0), usages: []float64{5}},
+	}
+	for i, test := range tests {
+		dataSeries := allSeries[i]
+		if dataSeries.label != test.label || !dataSeries.start.Equal(test.start) {
+			t.Errorf("expected series '%s' starting %s, got '%s' starting %s", test.label, test.start, dataSeries.label, dataSeries.start)
+		}
+		if len(dataSeries.usages) != len(test.usages) {
+			t.Errorf("expected usages %v, got %v", test.usages, dataSeries.usages)
+			continue
+		}
+		for month, usage := range test.usages {
+			if math.Abs(dataSeries.usages[month]-usage) > 1e-9 {
+				t.Errorf("expected usages %v, got %v", test.usages, dataSeries.usages)
+				break
+			}
+		}
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	tests := []struct {
+		name         string
+		objective    func([]float64) float64
+		initial      []float64
+		lower, upper []float64
+		want         []float64
+	}{
+		{
+			name: "minimum within the bounds",
+			objective: func(point []float64) float64 {
+				return math.Pow(point[0]-1, 2) + 3*math.Pow(point[1]+2, 2)
+			},
+			initial: []float64{0, 0},
+			lower:   []float64{-10, -10},
+			upper:   []float64{10, 10},
+			want:    []float64{1, -2},
+		},
+		{
+			name: "minimum outside of the bounds",
+			objective: func(point []float64) float64 {
+				return math.Pow(point[0]-2, 2) + math.Pow(point[1]+1, 2) + point[0]*point[1]/4
+			},
+			initial: []float64{0.5, 0.5},
+			lower:   []float64{0, 0},
+			upper:   []float64{1, 1},
+			want:    []float64{1, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			point, err := minimize(context.Background(), test.objective, test.initial, test.lower, test.upper)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, value := range test.want {
+				if math.Abs(point[i]-value) > 1e-3 {
+					t.Errorf("expected minimum at %v, got %v", test.want, point)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMinimizeStopsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	objective := func(point []float64) float64 { return point[0] * point[0] }
+	_, err := minimize(ctx, objective, []float64{1}, []float64{-2}, []float64{2})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
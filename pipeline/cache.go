@@ -0,0 +1,45 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/wisdom-oss/service-usage-forecasts/cache"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// cacheKey calculates the key under which the output of the forecast is
+// cached. The key changes if the script, the metadata, the parameters or the
+// usage data passed to the algorithm change
+func cacheKey(request Request, usageDataPoints []types.UsageDataPoint) (string, error) {
+	metadata, err := json.Marshal(request.Algorithm.Metadata)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize metadata: %w", err)
+	}
+	data, err := json.Marshal(usageDataPoints)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize usage data: %w", err)
+	}
+	return cache.Key(
+		[]byte(request.Algorithm.Identifier),
+		[]byte(request.Algorithm.Checksum),
+		metadata,
+		normalizeParameters(request.Parameters),
+		data,
+	), nil
+}
+
+// normalizeParameters re-encodes parameters given as json to remove the
+// influence of whitespace and the order of the keys. Other parameters are
+// returned unchanged
+func normalizeParameters(parameters []byte) []byte {
+	var decoded interface{}
+	if err := json.Unmarshal(parameters, &decoded); err != nil {
+		return parameters
+	}
+	normalized, err := json.Marshal(decoded)
+	if err != nil {
+		return parameters
+	}
+	return normalized
+}
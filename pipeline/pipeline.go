@@ -0,0 +1,282 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+
+	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/helpers"
+	"github.com/wisdom-oss/service-usage-forecasts/registry"
+	"github.com/wisdom-oss/service-usage-forecasts/repository"
+	"github.com/wisdom-oss/service-usage-forecasts/scheduler"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// ErrTimedOut is returned if the algorithm did not finish within the timeout
+// configured for the algorithm
+var ErrTimedOut = errors.New("algorithm did not finish within its timeout")
+
+// ErrEmptyArea is returned if the shapes or the geometry selecting the area
+// do not contain any municipality with recorded water usages
+var ErrEmptyArea = errors.New("selected area does not contain any municipality")
+
+// Request contains everything needed to execute a forecast
+type Request struct {
+	// Algorithm contains the algorithm used to calculate the forecast
+	Algorithm registry.Algorithm
+
+	// MunicipalKeys contains the keys of the municipals from which the water
+	// usages are used. Each key is used as a prefix for the selected area
+	MunicipalKeys []string
+
+	// Shapes contains the ids of the shapes from the geodata tables which
+	// select the municipalities whose water usages are used
+	Shapes []int64
+
+	// Geometry contains a GeoJSON geometry which selects the municipalities
+	// whose water usages are used. It may be nil
+	Geometry json.RawMessage
+
+	// ConsumerGroups contains the external identifiers of the consumer groups
+	// whose water usages are used. If no consumer groups are set, the usages
+	// of all consumer groups are used
+	ConsumerGroups []string
+
+	// Parameters contains the raw parameters passed to the algorithm
+	Parameters []byte
+
+	// BucketSize contains the size of the buckets used to aggregate the usage
+	// data if the algorithm uses buckets
+	BucketSize pgtype.Interval
+
+	// From restricts the usage data to usages recorded at or after the time.
+	// It may be nil
+	From *time.Time
+
+	// Until restricts the usage data to usages recorded at or before the
+	// time. It may be nil
+	Until *time.Time
+
+	// Started is called as soon as the algorithm is executed after waiting for
+	// the scheduler. It may be nil
+	Started func()
+
+	// Ticket contains the place in the queue of the scheduler if it has been
+	// taken before running the pipeline. If it is nil, the execution is
+	// placed into the queue once the output is not found in the cache
+	Ticket *scheduler.Ticket
+}
+
+// Result contains the outcome of a forecast
+type Result struct {
+	// Output contains the output written by the algorithm
+	Output []byte
+
+	// RunID contains the id under which the run has been recorded. It is
+	// empty if the run could not be recorded or the output has been cached
+	RunID string
+
+	// Cached indicates that the output has been taken from the cache instead
+	// of executing the algorithm
+	Cached bool
+}
+
+// Run executes the forecast described by the request and returns the output
+// of the algorithm.
+// The cache is checked before the execution is placed into the queue of the
+// scheduler, therefore cached outputs are returned even if the queue is full.
+// If the queue is full, scheduler.ErrQueueFull is returned. The ticket of the
+// request is discarded once the forecast finished.
+// Every execution of the algorithm is recorded in the run history, regardless
+// of its outcome.
+// If a cache is configured, the outputs of successful executions are cached
+// and reused for forecasts with the same algorithm, parameters and usage data.
+// The outputs of on-demand algorithms are not cached since their scripts are
+// only executed once.
+func Run(ctx context.Context, request Request) (Result, error) {
+	if request.Ticket != nil {
+		defer request.Ticket.Discard()
+	}
+
+	request, err := resolveArea(ctx, request)
+	if err != nil {
+		return Result{}, err
+	}
+
+	usageDataPoints, err := queryUsageData(ctx, request)
+	if err != nil {
+		return Result{}, err
+	}
+
+	useCache := globals.Cache != nil && request.Algorithm.Origin != registry.OriginOnDemand
+	var key string
+	if useCache {
+		key, err = cacheKey(request, usageDataPoints)
+		if err != nil {
+			return Result{}, err
+		}
+		if output, hit := globals.Cache.Get(key); hit {
+			log.Debug().Str("algorithm", request.Algorithm.Identifier).Msg("using cached output")
+			return Result{Output: annotateOutput(output, request), Cached: true}, nil
+		}
+	}
+
+	ticket := request.Ticket
+	if ticket == nil {
+		ticket, err = globals.Scheduler.Enqueue(request.Algorithm.Identifier, request.Algorithm.Metadata.MaxParallelExecutions)
+		if err != nil {
+			return Result{}, err
+		}
+		defer ticket.Discard()
+	}
+
+	output, duration, err := execute(ctx, request, ticket, usageDataPoints)
+	if duration == 0 {
+		// the algorithm has not been executed, therefore there is no run
+		// which could be recorded
+		return Result{}, err
+	}
+	runID := recordRun(ctx, request, usageDataPoints, output, duration, err)
+	if err != nil {
+		return Result{RunID: runID}, err
+	}
+	if useCache {
+		globals.Cache.Set(key, output)
+	}
+	return Result{Output: annotateOutput(output, request), RunID: runID}, nil
+}
+
+// annotateOutput adds the time range used to select the usage data into the
+// metadata of the output. Outputs which are no json objects are returned
+// unchanged
+func annotateOutput(output []byte, request Request) []byte {
+	if request.From == nil && request.Until == nil {
+		return output
+	}
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(output, &document); err != nil {
+		return output
+	}
+	meta := make(map[string]json.RawMessage)
+	if raw, set := document["meta"]; set {
+		if err := json.Unmarshal(raw, &meta); err != nil || meta == nil {
+			return output
+		}
+	}
+	if request.From != nil {
+		meta["from"], _ = json.Marshal(request.From)
+	}
+	if request.Until != nil {
+		meta["until"], _ = json.Marshal(request.Until)
+	}
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return output
+	}
+	document["meta"] = raw
+	annotated, err := json.Marshal(document)
+	if err != nil {
+		return output
+	}
+	return annotated
+}
+
+// execute calculates the forecast using the forecaster of the algorithm once
+// the scheduler allows it and serializes the result. If the algorithm has
+// been executed, the duration of the execution is returned
+func execute(ctx context.Context, request Request, ticket *scheduler.Ticket, usageDataPoints []types.UsageDataPoint) ([]byte, time.Duration, error) {
+	if request.Algorithm.Forecaster == nil {
+		return nil, 0, fmt.Errorf("algorithm '%s' has no forecaster", request.Algorithm.Identifier)
+	}
+
+	// now wait until the scheduler allows the algorithm to be executed
+	release, err := ticket.Wait(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
+	if request.Started != nil {
+		request.Started()
+	}
+
+	// now calculate the forecast and stop it if the context is cancelled or
+	// the execution takes longer than the algorithm is allowed to run
+	timeout := request.Algorithm.Timeout(globals.AlgorithmTimeout)
+	executionCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	result, err := request.Algorithm.Forecaster.Forecast(executionCtx, usageDataPoints, request.Parameters)
+	duration := time.Since(start)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		log.Warn().Str("algorithm", request.Algorithm.Identifier).Dur("timeout", timeout).Msg("algorithm timed out")
+		return nil, duration, ErrTimedOut
+	case errors.Is(err, registry.ErrInvalidOutput):
+		log.Warn().Err(err).Str("algorithm", request.Algorithm.Identifier).Msg("algorithm returned an invalid output")
+		return nil, duration, err
+	case err != nil:
+		var executionError *helpers.ExecutionError
+		if errors.As(err, &executionError) {
+			// the output of the algorithm is only logged since it may contain
+			// internal information, e.g., paths or the data of the forecast
+			log.Error().
+				Str("requestID", chiMiddleware.GetReqID(ctx)).
+				Str("algorithm", request.Algorithm.Identifier).
+				Int("exitCode", executionError.ExitCode).
+				Str("signal", executionError.Signal).
+				Bool("truncated", executionError.Truncated).
+				Str("stdout", executionError.Stdout).
+				Str("stderr", executionError.Stderr).
+				Msg("algorithm failed")
+		}
+		return nil, duration, fmt.Errorf("unable to run algorithm: %w", err)
+	}
+	log.Debug().Dur("duration", duration).Msg("algorithm finished")
+
+	output, err := json.Marshal(result)
+	if err != nil {
+		return nil, duration, fmt.Errorf("unable to serialize results: %w", err)
+	}
+	return output, duration, nil
+}
+
+// resolveArea resolves the shapes and the geometry of the request into the keys
+// of the municipalities within them and adds the keys to the municipal keys of
+// the request
+func resolveArea(ctx context.Context, request Request) (Request, error) {
+	if len(request.Shapes) == 0 && len(request.Geometry) == 0 {
+		return request, nil
+	}
+	usages := repository.NewUsages(globals.Db, globals.SqlQueries)
+	municipalities, err := usages.Municipalities(ctx, request.Shapes, request.Geometry)
+	if err != nil {
+		return request, err
+	}
+	if len(municipalities) == 0 && len(request.MunicipalKeys) == 0 {
+		return request, ErrEmptyArea
+	}
+	log.Debug().Int("municipalities", len(municipalities)).Msg("resolved selected area")
+	request.MunicipalKeys = append(slices.Clone(request.MunicipalKeys), municipalities...)
+	return request, nil
+}
+
+// queryUsageData pulls the usage data matching the request from the database
+func queryUsageData(ctx context.Context, request Request) ([]types.UsageDataPoint, error) {
+	usages := repository.NewUsages(globals.Db, globals.SqlQueries)
+	return usages.Select(ctx, repository.UsageSelection{
+		MunicipalKeys:  request.MunicipalKeys,
+		ConsumerGroups: request.ConsumerGroups,
+		UseBuckets:     request.Algorithm.Metadata.UseBuckets,
+		BucketSize:     request.BucketSize,
+		From:           request.From,
+		Until:          request.Until,
+	})
+}
@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+
+	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/helpers"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// recordRun stores the execution of the algorithm in the database and returns
+// the id of the stored run.
+// Since the history is not required for the forecast itself, errors are only
+// logged and an empty id is returned.
+func recordRun(ctx context.Context, request Request, usageDataPoints []types.UsageDataPoint, output []byte, duration time.Duration, runErr error) string {
+	// the run is also recorded if the client disconnected during the
+	// execution of the algorithm
+	ctx = context.WithoutCancel(ctx)
+
+	status := types.RunSucceeded
+	var exitCode *int
+	var signal *string
+	var reason *string
+	if runErr != nil {
+		switch {
+		case errors.Is(runErr, ErrTimedOut):
+			status = types.RunTimedOut
+		case errors.Is(runErr, context.Canceled):
+			status = types.RunCancelled
+		default:
+			status = types.RunFailed
+		}
+		message := runErr.Error()
+		reason = &message
+		output = nil
+	}
+	var exitError *exec.ExitError
+	if errors.As(runErr, &exitError) {
+		code := exitError.ExitCode()
+		exitCode = &code
+	} else if runErr == nil {
+		code := 0
+		exitCode = &code
+	}
+	var executionError *helpers.ExecutionError
+	if errors.As(runErr, &executionError) && executionError.Signal != "" {
+		signal = &executionError.Signal
+	}
+
+	// the algorithm output and parameters are stored as jsonb, therefore
+	// invalid json is not stored
+	var parameters []byte
+	if json.Valid(request.Parameters) {
+		parameters = request.Parameters
+	}
+	if !json.Valid(output) {
+		output = nil
+	}
+
+	var dataFrom, dataUntil pgtype.Timestamptz
+	for _, dataPoint := range usageDataPoints {
+		if !dataFrom.Valid || dataPoint.Date.Time.Before(dataFrom.Time) {
+			dataFrom = dataPoint.Date
+		}
+		if !dataUntil.Valid || dataPoint.Date.Time.After(dataUntil.Time) {
+			dataUntil = dataPoint.Date
+		}
+	}
+
+	consumerGroups := request.ConsumerGroups
+	if consumerGroups == nil {
+		consumerGroups = []string{}
+	}
+
+	query, err := globals.SqlQueries.Raw("insert-run")
+	if err != nil {
+		log.Error().Err(err).Msg("unable to prepare query for recording the run")
+		return ""
+	}
+	var id pgtype.UUID
+	err = globals.Db.QueryRow(ctx, query,
+		request.Algorithm.Identifier, parameters, request.MunicipalKeys, consumerGroups,
+		dataFrom, dataUntil, output, duration, status, exitCode, signal, reason,
+	).Scan(&id)
+	if err != nil {
+		log.Error().Err(err).Msg("unable to record the run")
+		return ""
+	}
+	value, _ := id.Value()
+	runID, _ := value.(string)
+	return runID
+}
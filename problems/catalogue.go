@@ -0,0 +1,127 @@
+// Package problems contains the catalogue of errors returned by the service.
+// The errors are described in the errors.json file and referenced by their
+// code, which allows clients to handle errors without parsing their texts
+package problems
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// CodeInternalError contains the code used for errors which are not part of
+// the catalogue
+const CodeInternalError = "INTERNAL_ERROR"
+
+// Definition describes a single error of the catalogue
+type Definition struct {
+	// Code contains the machine-readable identifier of the error
+	Code string `json:"code"`
+
+	// Type contains a URI reference identifying the problem type
+	Type string `json:"type"`
+
+	// Title contains a short, human-readable summary of the error
+	Title string `json:"title"`
+
+	// Description contains a human-readable description of the error focusing
+	// on its correction
+	Description string `json:"description"`
+
+	// HttpCode contains the status code sent together with the error
+	HttpCode int `json:"httpCode"`
+}
+
+// Catalogue contains the definitions of all errors returned by the service
+type Catalogue struct {
+	// definitions contains the definitions in the order of the file
+	definitions []Definition
+
+	// codes contains the definitions indexed by their code
+	codes map[string]Definition
+}
+
+// declared contains the codes of the errors created using New. They are
+// required to be part of every loaded catalogue
+var declared []string
+
+// Load reads the catalogue from the file and validates its definitions.
+// Every code used by the service needs to be defined in the catalogue
+func Load(path string) (*Catalogue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open error catalogue: %w", err)
+	}
+	defer file.Close()
+
+	var definitions []Definition
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&definitions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse error catalogue: %w", err)
+	}
+
+	catalogue := &Catalogue{
+		definitions: definitions,
+		codes:       make(map[string]Definition, len(definitions)),
+	}
+	for _, definition := range definitions {
+		if strings.TrimSpace(definition.Code) == "" {
+			return nil, errors.New("error definition without code")
+		}
+		if _, exists := catalogue.codes[definition.Code]; exists {
+			return nil, fmt.Errorf("error code '%s' defined multiple times", definition.Code)
+		}
+		if strings.TrimSpace(definition.Title) == "" {
+			return nil, fmt.Errorf("error code '%s' has no title", definition.Code)
+		}
+		if definition.HttpCode < 400 || definition.HttpCode > 599 {
+			return nil, fmt.Errorf("error code '%s' has invalid http code %d", definition.Code, definition.HttpCode)
+		}
+		catalogue.codes[definition.Code] = definition
+	}
+
+	var missing []string
+	for _, code := range append(slices.Clone(declared), CodeInternalError) {
+		if _, exists := catalogue.codes[code]; !exists {
+			missing = append(missing, code)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return nil, fmt.Errorf("error catalogue is missing the codes %s", strings.Join(slices.Compact(missing), ", "))
+	}
+	return catalogue, nil
+}
+
+// Definitions returns the definitions of the catalogue in the order of the
+// file
+func (c *Catalogue) Definitions() []Definition {
+	return slices.Clone(c.definitions)
+}
+
+// Lookup returns the definition of the error with the code
+func (c *Catalogue) Lookup(code string) (Definition, bool) {
+	definition, found := c.codes[code]
+	return definition, found
+}
+
+// internalError returns the definition used for errors which are not part of
+// the catalogue
+func (c *Catalogue) internalError() Definition {
+	if definition, found := c.Lookup(CodeInternalError); found {
+		return definition
+	}
+	return Definition{
+		Code:        CodeInternalError,
+		Type:        "https://www.rfc-editor.org/rfc/rfc9110#section-15.6.1",
+		Title:       "Internal Server Error",
+		Description: "An unexpected error occurred during the request and the service is unable to continue with your request. Please try again",
+		HttpCode:    http.StatusInternalServerError,
+	}
+}
@@ -0,0 +1,124 @@
+package problems
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	wisdomType "github.com/wisdom-oss/commonTypes/v2"
+	wisdomMiddleware "github.com/wisdom-oss/microservice-middlewares/v4"
+)
+
+// contentType contains the content type of the error responses
+const contentType = "application/problem+json; charset=utf-8"
+
+// Error references an error of the catalogue. It is sent to the error handler
+// like the errors of the wisdom middlewares
+type Error struct {
+	// Code contains the code of the error in the catalogue
+	Code string
+
+	// Error contains further information about the occurrence of the error.
+	// It is omitted in the response if it is empty
+	Error string
+
+	// Details contains structured information about the occurrence of the
+	// error. It is omitted in the response if it is nil
+	Details interface{}
+}
+
+// New declares an error of the catalogue. Loading a catalogue fails if it
+// does not define the code
+func New(code string) Error {
+	declared = append(declared, code)
+	return Error{Code: code}
+}
+
+// Response contains the error sent to the client. It extends the errors of
+// the wisdom services by the code of the error
+type Response struct {
+	wisdomType.WISdoMError
+
+	// Code contains the code of the error in the catalogue
+	Code string `json:"code,omitempty"`
+
+	// Details contains structured information about the occurrence of the
+	// error
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Response creates the response for the error using the definition from the
+// catalogue. Unknown codes result in an internal error
+func (c *Catalogue) Response(e Error) Response {
+	definition, found := c.Lookup(e.Code)
+	if !found {
+		log.Error().Str("code", e.Code).Msg("error code not found in catalogue")
+		definition = c.internalError()
+	}
+	return Response{
+		WISdoMError: wisdomType.WISdoMError{
+			Type:   definition.Type,
+			Status: definition.HttpCode,
+			Title:  definition.Title,
+			Detail: definition.Description,
+			Error:  e.Error,
+		},
+		Code:    definition.Code,
+		Details: e.Details,
+	}
+}
+
+// ErrorHandler returns a middleware which replaces the error handler of the
+// wisdom middlewares. The handlers send their errors using the same channels,
+// but the responses contain the code of the error.
+// Errors of the catalogue, errors of the wisdom middlewares and native errors
+// are accepted. Native errors are sent as internal error
+func ErrorHandler(catalogue *Catalogue) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			input := make(chan interface{})
+			statusChannel := make(chan bool)
+			done := make(chan struct{})
+			defer close(done)
+
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, wisdomMiddleware.ErrorChannelName, (chan<- interface{})(input))
+			ctx = context.WithValue(ctx, wisdomMiddleware.StatusChannelName, (<-chan bool)(statusChannel))
+
+			// the errors are handled asynchronously since the handlers wait
+			// for the status after sending their error. the goroutine stops
+			// after the first error or once the request has been handled
+			go func() {
+				select {
+				case data := <-input:
+					var response Response
+					switch e := data.(type) {
+					case Error:
+						response = catalogue.Response(e)
+					case wisdomType.WISdoMError:
+						response = Response{WISdoMError: e}
+					case error:
+						response = catalogue.Response(Error{Code: CodeInternalError, Error: e.Error()})
+					default:
+						response = catalogue.Response(Error{Code: CodeInternalError, Error: "invalid type provided to error handler"})
+					}
+					_ = response.send(w)
+					statusChannel <- true
+				case <-done:
+				}
+			}()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// send writes the response using the status of the error
+func (response Response) send(w http.ResponseWriter) error {
+	response.Instance, _ = os.Hostname()
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(response.Status)
+	return json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/wisdom-oss/service-usage-forecasts/helpers"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// Algorithm describes a single algorithm that has been loaded into the
+// registry
+type Algorithm struct {
+	// Identifier contains the identifier used in requests to select the
+	// algorithm
+	Identifier string
+
+	// Script contains the path to the script implementing the algorithm. For
+	// algorithms implemented in Go, the script is only used if the Go
+	// implementation is disabled and may be empty
+	Script string
+
+	// Forecaster calculates the forecasts of the algorithm, regardless of how
+	// the algorithm is implemented
+	Forecaster Forecaster
+
+	// Metadata contains the metadata read from the yaml file accompanying the
+	// script
+	Metadata types.AlgorithmMetadata
+
+	// Origin contains the origin of the source the algorithm has been loaded
+	// from
+	Origin Origin
+
+	// Checksum contains the hex-encoded sha256 checksum of the script at the
+	// time it has been loaded
+	Checksum string
+}
+
+// Information converts the algorithm into the representation used to inform
+// users about the available algorithms
+func (a Algorithm) Information() types.AlgorithmInformation {
+	var information types.AlgorithmInformation
+	information.Identifier = a.Identifier
+	information.Filename = filepath.Base(a.Script)
+	information.Origin = string(a.Origin)
+	information.Native = a.IsNative()
+	information.DisplayName = a.Metadata.DisplayName
+	information.Description = a.Metadata.Description
+	information.Parameter = a.Metadata.Parameters
+	information.BucketConfiguration.UseBuckets = a.Metadata.UseBuckets
+	information.BucketConfiguration.BucketSize = a.Metadata.BucketSize
+	information.BucketConfiguration.AllowOverride = a.Metadata.UseBuckets && a.Metadata.AllowBucketOverride
+	return information
+}
+
+// IsNative reports if the algorithm is implemented in Go instead of being
+// executed as script
+func (a Algorithm) IsNative() bool {
+	if a.Forecaster == nil {
+		return false
+	}
+	_, isScript := a.Forecaster.(scriptForecaster)
+	return !isScript
+}
+
+// Timeout returns the maximal duration of a single execution of the algorithm.
+// If the metadata does not specify a timeout, the fallback is returned
+func (a Algorithm) Timeout(fallback time.Duration) time.Duration {
+	// the timeout has already been validated while loading the algorithm
+	timeout, err := time.ParseDuration(a.Metadata.Timeout)
+	if err != nil {
+		return fallback
+	}
+	return timeout
+}
+
+// BucketSize returns the size of the buckets used to aggregate the usage data.
+// If an override is supplied, it replaces the bucket size from the metadata
+// if the metadata allows it
+func (a Algorithm) BucketSize(override string) (pgtype.Interval, error) {
+	if strings.TrimSpace(override) == "" {
+		// the bucket size has already been validated while loading the
+		// algorithm
+		return helpers.ParseInterval(a.Metadata.BucketSize)
+	}
+	if !a.Metadata.UseBuckets || !a.Metadata.AllowBucketOverride {
+		return pgtype.Interval{}, errors.New("the algorithm does not allow changing the bucket size")
+	}
+	return helpers.ParseInterval(override)
+}
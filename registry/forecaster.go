@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/wisdom-oss/service-usage-forecasts/helpers"
+	"github.com/wisdom-oss/service-usage-forecasts/native"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// Forecaster calculates the forecasts of an algorithm. It hides how the
+// algorithm is implemented, which allows treating scripts and algorithms
+// written in Go the same way
+type Forecaster interface {
+	// Metadata returns the metadata describing the algorithm
+	Metadata() types.AlgorithmMetadata
+
+	// Parameters returns the parameters accepted by the algorithm
+	Parameters() map[string]types.Parameter
+
+	// Forecast calculates a forecast for the usage data. The parameters are
+	// supplied as json and have already been validated against the parameters
+	// of the algorithm.
+	// The forecast needs to stop as soon as the context is cancelled.
+	Forecast(ctx context.Context, usageDataPoints []types.UsageDataPoint, parameters []byte) (types.ForecastResult, error)
+}
+
+// scriptForecaster calculates the forecasts of an algorithm implemented as
+// script by executing the script. If the sandbox is nil, the script is
+// executed without restrictions
+type scriptForecaster struct {
+	path     string
+	metadata types.AlgorithmMetadata
+	sandbox  *helpers.Sandbox
+}
+
+func (s scriptForecaster) Metadata() types.AlgorithmMetadata {
+	return s.metadata
+}
+
+func (s scriptForecaster) Parameters() map[string]types.Parameter {
+	return s.metadata.Parameters
+}
+
+// Forecast writes the usage data and parameters into temporary files and
+// calls the script. Afterward, the output written by the script is decoded and
+// validated against the schema of the results. If the output violates the
+// schema, an error wrapping ErrInvalidOutput is returned
+func (s scriptForecaster) Forecast(ctx context.Context, usageDataPoints []types.UsageDataPoint, parameters []byte) (types.ForecastResult, error) {
+	// write the usage data points and parameters into a temporary directory
+	// which is removed after the forecast
+	workingDirectory, err := os.MkdirTemp("", "forecast-*")
+	if err != nil {
+		return types.ForecastResult{}, fmt.Errorf("unable to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(workingDirectory)
+
+	log.Debug().Msg("writing usage data to file")
+	dataFileName := filepath.Join(workingDirectory, "forecast.input")
+	dataFile, err := os.Create(dataFileName)
+	if err != nil {
+		return types.ForecastResult{}, fmt.Errorf("unable to create temporary data file: %w", err)
+	}
+	err = json.NewEncoder(dataFile).Encode(usageDataPoints)
+	_ = dataFile.Close()
+	if err != nil {
+		return types.ForecastResult{}, fmt.Errorf("unable to write usage data to file: %w", err)
+	}
+	log.Debug().Msg("wrote data to temporary file")
+
+	parameterFileName := filepath.Join(workingDirectory, "forecast.parameter")
+	err = os.WriteFile(parameterFileName, parameters, 0o600)
+	if err != nil {
+		return types.ForecastResult{}, fmt.Errorf("unable to write parameter file: %w", err)
+	}
+	log.Debug().Int("bytes", len(parameters)).Msg("wrote parameter")
+
+	// the output file is created before executing the algorithm to allow the
+	// algorithm to open it for writing
+	outputFileName := filepath.Join(workingDirectory, "forecast.output")
+	err = os.WriteFile(outputFileName, nil, 0o600)
+	if err != nil {
+		return types.ForecastResult{}, fmt.Errorf("unable to create temporary output file: %w", err)
+	}
+
+	log.Debug().Msg("calling algorithm")
+	err = helpers.CallAlgorithm(ctx, s.sandbox, s.path, dataFileName, outputFileName, parameterFileName)
+	if err != nil {
+		return types.ForecastResult{}, err
+	}
+
+	output, err := os.ReadFile(outputFileName)
+	if err != nil {
+		return types.ForecastResult{}, fmt.Errorf("unable to read results: %w", err)
+	}
+	return decodeOutput(output)
+}
+
+// nativeForecaster calculates the forecasts of an algorithm implemented in
+// the native package
+type nativeForecaster struct {
+	implementation native.Implementation
+}
+
+func (n nativeForecaster) Metadata() types.AlgorithmMetadata {
+	return n.implementation.Metadata
+}
+
+func (n nativeForecaster) Parameters() map[string]types.Parameter {
+	return n.implementation.Metadata.Parameters
+}
+
+// Forecast calculates the forecast natively
+func (n nativeForecaster) Forecast(ctx context.Context, usageDataPoints []types.UsageDataPoint, parameters []byte) (types.ForecastResult, error) {
+	log.Debug().Msg("calculating forecast natively")
+	return n.implementation.Forecast(ctx, usageDataPoints, parameters)
+}
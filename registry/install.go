@@ -0,0 +1,214 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// ErrInvalidAlgorithm is returned if the script or metadata supplied for an
+// installation are not valid
+var ErrInvalidAlgorithm = errors.New("invalid algorithm")
+
+// ErrAlgorithmExists is returned if an algorithm shall be installed without
+// replacing an already existing algorithm
+var ErrAlgorithmExists = errors.New("algorithm already exists")
+
+// ErrInternalAlgorithm is returned if an algorithm shipped with the service
+// shall be replaced or removed
+var ErrInternalAlgorithm = errors.New("internal algorithms can not be modified")
+
+// ErrUnknownAlgorithm is returned if an algorithm shall be removed which does
+// not exist
+var ErrUnknownAlgorithm = errors.New("unknown algorithm")
+
+// ErrNoExternalSource is returned if the registry has no source which accepts
+// new algorithms
+var ErrNoExternalSource = errors.New("no external algorithm source configured")
+
+// identifierPattern describes the identifiers accepted for new algorithms
+var identifierPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// reservedIdentifiers contains the identifiers which are used by other routes
+// of the service and would make the algorithm unreachable
+var reservedIdentifiers = []string{"errors", "invalid-algorithms", "jobs", OnDemandIdentifier, "runs"}
+
+// pythonEntryPointPattern matches the guard used by python scripts to run
+// their main code when executed as script
+var pythonEntryPointPattern = regexp.MustCompile(`(?m)^if\s+__name__\s*==\s*["']__main__["']\s*:`)
+
+// Install validates the script and metadata and stores them in the external
+// source of the registry. Afterward, the registry is reloaded to make the
+// algorithm available immediately.
+// If replace is false, the installation fails if an algorithm with the same
+// identifier is already available. Algorithms from internal sources can never
+// be replaced.
+func (r *Registry) Install(identifier, scriptName string, script, metadata []byte, replace bool) (Algorithm, error) {
+	r.installLock.Lock()
+	defer r.installLock.Unlock()
+
+	source, found := r.externalSource()
+	if !found {
+		return Algorithm{}, ErrNoExternalSource
+	}
+
+	if !identifierPattern.MatchString(identifier) {
+		return Algorithm{}, fmt.Errorf("%w: identifier '%s' may only contain lowercase letters, digits, '-' and '_'", ErrInvalidAlgorithm, identifier)
+	}
+	if slices.Contains(reservedIdentifiers, identifier) {
+		return Algorithm{}, fmt.Errorf("%w: identifier '%s' is reserved by the service", ErrInvalidAlgorithm, identifier)
+	}
+
+	extension := strings.ToLower(filepath.Ext(scriptName))
+	if !slices.Contains(SupportedExtensions, extension) {
+		return Algorithm{}, fmt.Errorf("%w: unsupported script type '%s'", ErrInvalidAlgorithm, extension)
+	}
+
+	if existing, exists := r.Lookup(identifier); exists {
+		if existing.Origin != OriginExternal {
+			return Algorithm{}, ErrInternalAlgorithm
+		}
+		if !replace {
+			return Algorithm{}, ErrAlgorithmExists
+		}
+	}
+
+	parsedMetadata, err := ParseMetadata(metadata)
+	if err == nil {
+		err = validateSandbox(parsedMetadata, source.Origin)
+	}
+	if err == nil {
+		err = validateEntryPoint(extension, script)
+	}
+	if err != nil {
+		return Algorithm{}, fmt.Errorf("%w: %w", ErrInvalidAlgorithm, err)
+	}
+
+	// remove scripts using another extension to prevent the identifier from
+	// being used twice in the external source
+	scriptPath := filepath.Join(source.Directory, identifier+extension)
+	for _, supportedExtension := range SupportedExtensions {
+		if supportedExtension == extension {
+			continue
+		}
+		err := os.Remove(filepath.Join(source.Directory, identifier+supportedExtension))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Algorithm{}, fmt.Errorf("unable to remove previous script: %w", err)
+		}
+	}
+
+	err = writeFileAtomically(filepath.Join(source.Directory, identifier+metadataExtension), metadata, 0o644)
+	if err != nil {
+		return Algorithm{}, fmt.Errorf("unable to store metadata: %w", err)
+	}
+	err = writeFileAtomically(scriptPath, script, 0o644)
+	if err != nil {
+		return Algorithm{}, fmt.Errorf("unable to store script: %w", err)
+	}
+
+	err = r.Load()
+	if err != nil {
+		return Algorithm{}, err
+	}
+	if err, failed := r.Failures()[scriptPath]; failed {
+		return Algorithm{}, fmt.Errorf("%w: %w", ErrInvalidAlgorithm, err)
+	}
+	algorithm, _ := r.Lookup(identifier)
+	return algorithm, nil
+}
+
+// Remove deletes the script and metadata of an algorithm from the external
+// source and reloads the registry afterward
+func (r *Registry) Remove(identifier string) error {
+	r.installLock.Lock()
+	defer r.installLock.Unlock()
+
+	algorithm, exists := r.Lookup(identifier)
+	if !exists {
+		return ErrUnknownAlgorithm
+	}
+	if algorithm.Origin != OriginExternal {
+		return ErrInternalAlgorithm
+	}
+
+	err := os.Remove(algorithm.Script)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to remove script: %w", err)
+	}
+	metadataPath := filepath.Join(filepath.Dir(algorithm.Script), identifier+metadataExtension)
+	err = os.Remove(metadataPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to remove metadata: %w", err)
+	}
+	return r.Load()
+}
+
+// ParseMetadata strictly decodes the metadata and validates it. Unknown fields
+// are reported as errors to detect typos in uploaded metadata files
+func ParseMetadata(data []byte) (types.AlgorithmMetadata, error) {
+	var metadata types.AlgorithmMetadata
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(&metadata)
+	if err != nil {
+		return types.AlgorithmMetadata{}, fmt.Errorf("unable to parse metadata: %w", err)
+	}
+	err = validateMetadata(metadata)
+	if err != nil {
+		return types.AlgorithmMetadata{}, err
+	}
+	return metadata, nil
+}
+
+// validateEntryPoint checks if the script is executable by the service.
+// Python scripts need to guard their main code
+func validateEntryPoint(extension string, script []byte) error {
+	if extension == ".py" && !pythonEntryPointPattern.Match(script) {
+		return errors.New("python script does not contain an 'if __name__ == \"__main__\":' entry point")
+	}
+	return nil
+}
+
+// externalSource returns the first source containing external algorithms
+func (r *Registry) externalSource() (Source, bool) {
+	for _, source := range r.sources {
+		if source.Origin == OriginExternal {
+			return source, true
+		}
+	}
+	return Source{}, false
+}
+
+// writeFileAtomically writes the data into a temporary file next to the
+// target and renames it afterward. This prevents the watcher from picking up
+// partially written files
+func writeFileAtomically(path string, data []byte, mode os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(file.Name(), mode)
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wisdom-oss/service-usage-forecasts/helpers"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// ErrSandboxUnavailable is returned if an on-demand algorithm shall be
+// created, but the scripts can not be executed in a sandbox
+var ErrSandboxUnavailable = errors.New("on-demand algorithms require the sandbox")
+
+// OnDemandIdentifier contains the identifier used for every on-demand
+// algorithm
+const OnDemandIdentifier = "on-demand"
+
+const (
+	// onDemandMemory contains the address space in mebibytes available to
+	// on-demand algorithms
+	onDemandMemory = 1024
+
+	// onDemandOpenFiles contains the number of files on-demand algorithms may
+	// open at the same time
+	onDemandOpenFiles = 64
+)
+
+// OnDemand creates an algorithm which executes the supplied script once
+// without adding it to the registry. The script is stored in a temporary
+// directory until the returned function is called.
+// The script is validated like uploaded scripts. The metadata is optional,
+// without it the parameters are passed to the script unchanged. Regardless of
+// the metadata, the script is executed in a strict sandbox without network
+// access and may not run longer than the timeout.
+func (r *Registry) OnDemand(scriptName string, script, metadata []byte, timeout time.Duration) (Algorithm, func(), error) {
+	r.lock.RLock()
+	unsandboxed := r.unsandboxed
+	r.lock.RUnlock()
+	if unsandboxed || !helpers.SandboxSupported() {
+		return Algorithm{}, nil, ErrSandboxUnavailable
+	}
+
+	extension := strings.ToLower(filepath.Ext(scriptName))
+	if !slices.Contains(SupportedExtensions, extension) {
+		return Algorithm{}, nil, fmt.Errorf("%w: unsupported script type '%s'", ErrInvalidAlgorithm, extension)
+	}
+	if err := validateEntryPoint(extension, script); err != nil {
+		return Algorithm{}, nil, fmt.Errorf("%w: %w", ErrInvalidAlgorithm, err)
+	}
+
+	parsedMetadata := types.AlgorithmMetadata{DisplayName: "On-demand forecast"}
+	if len(metadata) > 0 {
+		var err error
+		parsedMetadata, err = ParseMetadata(metadata)
+		if err != nil {
+			return Algorithm{}, nil, fmt.Errorf("%w: %w", ErrInvalidAlgorithm, err)
+		}
+	}
+	// the timeout of the metadata is only used if it is shorter than the
+	// timeout for on-demand algorithms
+	if configured, err := time.ParseDuration(parsedMetadata.Timeout); err != nil || configured > timeout {
+		parsedMetadata.Timeout = timeout.String()
+	}
+	parsedMetadata.Sandbox = types.SandboxConfiguration{
+		CPUTime:   parsedMetadata.Timeout,
+		Memory:    onDemandMemory,
+		OpenFiles: onDemandOpenFiles,
+	}
+	sandbox, err := helpers.NewSandbox(parsedMetadata.Sandbox)
+	if err != nil {
+		return Algorithm{}, nil, fmt.Errorf("%w: %w", ErrInvalidAlgorithm, err)
+	}
+
+	// the directory is readable by everyone since the script is executed by
+	// the user of the sandbox
+	directory, err := os.MkdirTemp("", "on-demand-*")
+	if err != nil {
+		return Algorithm{}, nil, fmt.Errorf("unable to create temporary directory: %w", err)
+	}
+	remove := func() { _ = os.RemoveAll(directory) }
+	err = os.Chmod(directory, 0o755)
+	if err != nil {
+		remove()
+		return Algorithm{}, nil, fmt.Errorf("unable to change permissions of temporary directory: %w", err)
+	}
+	scriptPath := filepath.Join(directory, OnDemandIdentifier+extension)
+	err = os.WriteFile(scriptPath, script, 0o644)
+	if err != nil {
+		remove()
+		return Algorithm{}, nil, fmt.Errorf("unable to store script: %w", err)
+	}
+
+	checksum := sha256.Sum256(script)
+	return Algorithm{
+		Identifier: OnDemandIdentifier,
+		Script:     scriptPath,
+		Forecaster: scriptForecaster{path: scriptPath, metadata: parsedMetadata, sandbox: sandbox},
+		Metadata:   parsedMetadata,
+		Origin:     OriginOnDemand,
+		Checksum:   hex.EncodeToString(checksum[:]),
+	}, remove, nil
+}
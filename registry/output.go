@@ -0,0 +1,143 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// ErrInvalidOutput is returned if the output of the algorithm does not match
+// the schema of the forecast results
+var ErrInvalidOutput = errors.New("algorithm returned an invalid output")
+
+// maxOutputProblems limits the number of schema violations listed in the
+// error for a single output
+const maxOutputProblems = 10
+
+// rawOutput mirrors the forecast result but uses pointers for the required
+// fields to detect missing values
+type rawOutput struct {
+	Meta *types.ForecastMetadata `json:"meta"`
+	Data *[]struct {
+		Label       *string    `json:"label"`
+		X           *float64   `json:"x"`
+		Y           *float64   `json:"y"`
+		Date        *time.Time `json:"date"`
+		Uncertainty *[]float64 `json:"uncertainty"`
+	} `json:"data"`
+}
+
+// decodeOutput decodes the output of the algorithm into a forecast result and
+// validates it against the schema of the results. If the output violates the
+// schema, an error wrapping ErrInvalidOutput and describing the violations is
+// returned
+func decodeOutput(output []byte) (types.ForecastResult, error) {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return types.ForecastResult{}, fmt.Errorf("%w: the algorithm did not write any output", ErrInvalidOutput)
+	}
+
+	var document rawOutput
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	err := decoder.Decode(&document)
+	if err != nil {
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &typeError) {
+			if typeError.Field == "" {
+				return types.ForecastResult{}, fmt.Errorf("%w: output needs to be a json object", ErrInvalidOutput)
+			}
+			return types.ForecastResult{}, fmt.Errorf("%w: %s: expected %s but got %s", ErrInvalidOutput, typeError.Field, jsonType(typeError.Type), typeError.Value)
+		}
+		return types.ForecastResult{}, fmt.Errorf("%w: output is not valid json: %s", ErrInvalidOutput, err.Error())
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return types.ForecastResult{}, fmt.Errorf("%w: output contains data after the result", ErrInvalidOutput)
+	}
+
+	var problems []string
+	if document.Meta == nil {
+		problems = append(problems, "meta: missing")
+	}
+	if document.Data == nil {
+		problems = append(problems, "data: missing")
+	}
+
+	var result types.ForecastResult
+	if document.Meta != nil {
+		result.Meta = *document.Meta
+	}
+	if document.Data != nil {
+		result.Data = make([]types.ForecastDataPoint, 0, len(*document.Data))
+		for i, dataPoint := range *document.Data {
+			field := fmt.Sprintf("data[%d]", i)
+			if dataPoint.Label == nil || strings.TrimSpace(*dataPoint.Label) == "" {
+				problems = append(problems, field+".label: missing")
+			}
+			if dataPoint.X == nil {
+				problems = append(problems, field+".x: missing")
+			}
+			if dataPoint.Y == nil {
+				problems = append(problems, field+".y: missing")
+			}
+			var uncertainty *[2]float64
+			if dataPoint.Uncertainty != nil {
+				bounds := *dataPoint.Uncertainty
+				switch {
+				case len(bounds) != 2:
+					problems = append(problems, field+".uncertainty: needs to contain exactly two values")
+				case bounds[0] > bounds[1]:
+					problems = append(problems, field+".uncertainty: lower bound exceeds upper bound")
+				default:
+					uncertainty = &[2]float64{bounds[0], bounds[1]}
+				}
+			}
+			if len(problems) >= maxOutputProblems {
+				break
+			}
+
+			converted := types.ForecastDataPoint{Date: dataPoint.Date, Uncertainty: uncertainty}
+			if dataPoint.Label != nil {
+				converted.Label = *dataPoint.Label
+			}
+			if dataPoint.X != nil {
+				converted.X = *dataPoint.X
+			}
+			if dataPoint.Y != nil {
+				converted.Y = *dataPoint.Y
+			}
+			result.Data = append(result.Data, converted)
+		}
+	}
+
+	if len(problems) > 0 {
+		if len(problems) > maxOutputProblems {
+			problems = problems[:maxOutputProblems]
+		}
+		return types.ForecastResult{}, fmt.Errorf("%w: %s", ErrInvalidOutput, strings.Join(problems, "; "))
+	}
+	return result, nil
+}
+
+// jsonType returns the name of the json type a go value is decoded from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Pointer:
+		return jsonType(t.Elem())
+	default:
+		return "object"
+	}
+}
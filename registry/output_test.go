@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/wisdom-oss/service-usage-forecasts/helpers"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+func TestDecodeOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{
+			name:   "valid output",
+			output: `{"meta": {"rScores": {"a": 1}, "realDataUntil": {"a": 2020}}, "data": [{"label": "a", "x": 2021, "y": 1.5, "uncertainty": [1, 2]}]}`,
+		},
+		{
+			name:    "empty output",
+			output:  " \n",
+			wantErr: "did not write any output",
+		},
+		{
+			name:    "string as x-value",
+			output:  `{"meta": {}, "data": [{"label": "a", "x": "2021", "y": 1}]}`,
+			wantErr: "expected number but got string",
+		},
+		{
+			name:    "missing fields",
+			output:  `{"data": [{"x": 2021}]}`,
+			wantErr: "meta: missing; data[0].label: missing; data[0].y: missing",
+		},
+		{
+			name:    "reversed uncertainty",
+			output:  `{"meta": {}, "data": [{"label": "a", "x": 2021, "y": 1, "uncertainty": [2, 1]}]}`,
+			wantErr: "lower bound exceeds upper bound",
+		},
+		{
+			name:    "data after the result",
+			output:  `{"meta": {}, "data": []} {}`,
+			wantErr: "data after the result",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := decodeOutput([]byte(test.output))
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidOutput) || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("expected error containing '%s', got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+// testUsageData creates monthly usages of two municipalities over ten years
+// with a linear trend
+func testUsageData() []types.UsageDataPoint {
+	var usageDataPoints []types.UsageDataPoint
+	for _, municipal := range []string{"031510000000", "031520000000"} {
+		for month := 0; month < 120; month++ {
+			usageDataPoints = append(usageDataPoints, types.UsageDataPoint{
+				Municipal: municipal,
+				UsageType: pgtype.UUID{Bytes: [16]byte{0x01}, Valid: true},
+				Date:      pgtype.Timestamptz{Time: time.Date(2011, time.Month(month+1), 1, 0, 0, 0, 0, time.UTC), Valid: true},
+				Amount:    100 + float64(month),
+			})
+		}
+	}
+	return usageDataPoints
+}
+
+// TestDecodeBundledScriptOutputs executes every bundled script and decodes its
+// output to ensure the scripts match the schema of the results. Scripts whose
+// interpreter or packages are not installed are skipped
+func TestDecodeBundledScriptOutputs(t *testing.T) {
+	scripts, err := filepath.Glob(filepath.Join("..", "algorithms", "*.py"))
+	if err != nil || len(scripts) == 0 {
+		t.Fatalf("unable to find bundled scripts: %v", err)
+	}
+
+	for _, script := range scripts {
+		t.Run(filepath.Base(script), func(t *testing.T) {
+			algorithm, err := loadAlgorithm(script, OriginInternal, false)
+			if err != nil {
+				t.Fatalf("unable to load algorithm: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			result, err := algorithm.Forecaster.Forecast(ctx, testUsageData(), []byte("{}"))
+			var executionError *helpers.ExecutionError
+			switch {
+			case errors.Is(err, exec.ErrNotFound):
+				t.Skip("python interpreter not installed")
+			case errors.As(err, &executionError) && strings.Contains(executionError.Stderr, "ModuleNotFoundError"):
+				t.Skipf("packages of the script not installed: %s", executionError.Excerpt())
+			case err != nil:
+				t.Fatalf("forecast failed: %v", err)
+			}
+
+			// every label may only contain a single value per year and the
+			// forecast needs to continue after the real data
+			seen := make(map[string]bool)
+			forecasted := make(map[string]int)
+			for _, dataPoint := range result.Data {
+				key := fmt.Sprintf("%s %g", dataPoint.Label, dataPoint.X)
+				if seen[key] {
+					t.Errorf("x-value %g of '%s' returned multiple times", dataPoint.X, dataPoint.Label)
+				}
+				seen[key] = true
+
+				realDataUntil, set := result.Meta.RealDataUntil[dataPoint.Label]
+				if !set {
+					t.Fatalf("real data of '%s' missing in metadata", dataPoint.Label)
+				}
+				if dataPoint.X > realDataUntil {
+					forecasted[dataPoint.Label]++
+				}
+			}
+			for label := range result.Meta.RealDataUntil {
+				if forecasted[label] == 0 {
+					t.Errorf("no forecast returned for '%s'", label)
+				}
+			}
+		})
+	}
+}
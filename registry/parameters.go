@@ -0,0 +1,165 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// ErrInvalidParameters is returned if the parameters supplied for a forecast
+// do not match the parameters declared in the metadata of the algorithm
+var ErrInvalidParameters = errors.New("invalid parameters")
+
+// parameterTypes contains the types of parameters which can be validated
+var parameterTypes = []string{"int", "float", "str", "bool", "list"}
+
+// PrepareParameters validates the parameters supplied as a json object against
+// the parameters declared in the metadata of the algorithm and merges them with
+// the default values.
+// Empty parameters are accepted and result in the default values.
+// If the parameters are invalid, the returned error wraps ErrInvalidParameters
+// and lists every offending field.
+// On-demand algorithms without declared parameters accept every parameter.
+func (a Algorithm) PrepareParameters(raw []byte) ([]byte, error) {
+	supplied := make(map[string]interface{})
+	if len(bytes.TrimSpace(raw)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&supplied); err != nil {
+			return nil, fmt.Errorf("%w: parameters need to be a json object: %w", ErrInvalidParameters, err)
+		}
+		if decoder.More() {
+			return nil, fmt.Errorf("%w: parameters need to be a single json object", ErrInvalidParameters)
+		}
+	}
+
+	if a.Origin == OriginOnDemand && a.Metadata.Parameters == nil {
+		return json.Marshal(supplied)
+	}
+
+	var problems []string
+	for name, value := range supplied {
+		definition, known := a.Metadata.Parameters[name]
+		if !known {
+			problems = append(problems, fmt.Sprintf("%s: unknown parameter", name))
+			continue
+		}
+		if err := validateParameter(definition, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("%w: %s", ErrInvalidParameters, strings.Join(problems, "; "))
+	}
+
+	// now add the default values of the parameters which have not been set
+	for name, definition := range a.Metadata.Parameters {
+		if _, set := supplied[name]; set || definition.DefaultValue == nil {
+			continue
+		}
+		supplied[name] = definition.DefaultValue
+	}
+
+	parameters, err := json.Marshal(supplied)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize parameters: %w", err)
+	}
+	return parameters, nil
+}
+
+// validateParameter checks a single value against the definition of the
+// parameter
+func validateParameter(definition types.Parameter, value interface{}) error {
+	var numeric *float64
+	var length *int
+
+	switch definition.Type {
+	case "int":
+		number, ok := value.(json.Number)
+		if !ok {
+			return errors.New("expected an integer")
+		}
+		integer, err := number.Int64()
+		if err != nil {
+			return errors.New("expected an integer")
+		}
+		converted := float64(integer)
+		numeric = &converted
+	case "float":
+		number, ok := value.(json.Number)
+		if !ok {
+			return errors.New("expected a number")
+		}
+		converted, err := number.Float64()
+		if err != nil || math.IsInf(converted, 0) {
+			return errors.New("expected a number")
+		}
+		numeric = &converted
+	case "str":
+		text, ok := value.(string)
+		if !ok {
+			return errors.New("expected a string")
+		}
+		characters := len([]rune(text))
+		length = &characters
+	case "bool":
+		if _, ok := value.(bool); !ok {
+			return errors.New("expected a boolean")
+		}
+	case "list":
+		list, ok := value.([]interface{})
+		if !ok {
+			return errors.New("expected a list")
+		}
+		elements := len(list)
+		length = &elements
+	default:
+		return fmt.Errorf("unsupported parameter type '%s'", definition.Type)
+	}
+
+	if len(definition.Enums) > 0 && !slices.Contains(definition.Enums, fmt.Sprint(value)) {
+		return fmt.Errorf("value needs to be one of '%s'", strings.Join(definition.Enums, "', '"))
+	}
+
+	// the limits apply to the value of numbers and to the length of strings
+	// and lists
+	switch {
+	case numeric != nil:
+		return validateLimits(definition, *numeric, "value")
+	case length != nil:
+		return validateLimits(definition, float64(*length), "length")
+	}
+	return nil
+}
+
+// validateLimits checks the value or length of a parameter against the limits
+// of its definition
+func validateLimits(definition types.Parameter, value float64, subject string) error {
+	if definition.Min != nil {
+		minimum := float64(*definition.Min)
+		if definition.ExclusiveMin && value <= minimum {
+			return fmt.Errorf("%s needs to be larger than %d", subject, *definition.Min)
+		}
+		if value < minimum {
+			return fmt.Errorf("%s may not be smaller than %d", subject, *definition.Min)
+		}
+	}
+	if definition.Max != nil {
+		maximum := float64(*definition.Max)
+		if definition.ExclusiveMax && value >= maximum {
+			return fmt.Errorf("%s needs to be smaller than %d", subject, *definition.Max)
+		}
+		if value > maximum {
+			return fmt.Errorf("%s may not be larger than %d", subject, *definition.Max)
+		}
+	}
+	return nil
+}
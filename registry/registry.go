@@ -0,0 +1,331 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/wisdom-oss/service-usage-forecasts/helpers"
+	"github.com/wisdom-oss/service-usage-forecasts/native"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// SupportedExtensions contains the file extensions of the scripts which are
+// recognized as algorithms
+var SupportedExtensions = []string{".py"}
+
+// Registry contains the algorithms found in the configured sources. The
+// directories of the sources are only scanned when calling Load, which allows
+// serving requests without accessing the file system
+type Registry struct {
+	// sources contains the sources from which the algorithms are loaded ordered
+	// by their precedence
+	sources []Source
+
+	// lock protects the algorithms and failures during a reload
+	lock sync.RWMutex
+
+	// installLock serializes the installation and removal of algorithms
+	installLock sync.Mutex
+
+	// algorithms contains the successfully loaded algorithms indexed by their
+	// identifier
+	algorithms map[string]Algorithm
+
+	// failures contains the errors that occurred while loading an algorithm
+	// indexed by the file that caused the error. Failures of algorithms
+	// implemented in Go are indexed by their identifier
+	failures map[string]error
+
+	// registrations contains the algorithms implemented in Go which are added
+	// to the algorithms on every load
+	registrations []registration
+
+	// unsandboxed disables the sandbox for every script, regardless of their
+	// metadata
+	unsandboxed bool
+}
+
+// registration contains an algorithm implemented in Go and the identifier it
+// has been registered with
+type registration struct {
+	identifier string
+	forecaster Forecaster
+}
+
+// New creates a new, empty registry which loads its algorithms from the
+// supplied sources.
+// If an identifier is provided by multiple sources, the algorithm from the
+// source supplied first is used and the others are recorded as failures.
+func New(sources ...Source) *Registry {
+	return &Registry{
+		sources:    sources,
+		algorithms: make(map[string]Algorithm),
+		failures:   make(map[string]error),
+	}
+}
+
+// Register adds an algorithm implemented in Go to the registry. It is treated
+// as internal algorithm and replaces the script using the same identifier,
+// which is kept as fallback if the algorithm is not registered.
+// Registering an identifier again replaces the previous forecaster.
+// The algorithm is added during the next load.
+func (r *Registry) Register(identifier string, forecaster Forecaster) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.registrations = slices.DeleteFunc(r.registrations, func(existing registration) bool {
+		return existing.identifier == identifier
+	})
+	r.registrations = append(r.registrations, registration{identifier, forecaster})
+}
+
+// UseNative registers the algorithms implemented in the native package
+func (r *Registry) UseNative(implementations ...native.Implementation) {
+	for _, implementation := range implementations {
+		r.Register(implementation.Identifier, nativeForecaster{implementation})
+	}
+}
+
+// DisableSandbox executes every script without sandbox, regardless of their
+// metadata. This is required if the service is unable to create sandboxes,
+// e.g., since it does not run as root. The change applies to the next load
+func (r *Registry) DisableSandbox() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.unsandboxed = true
+}
+
+// Load scans the source directories of the registry and replaces the currently
+// loaded algorithms with the ones found.
+// Algorithms with missing or broken metadata are not loaded and recorded as
+// failures instead.
+// An error is only returned if one of the directories is not readable.
+func (r *Registry) Load() error {
+	algorithms := make(map[string]Algorithm)
+	failures := make(map[string]error)
+
+	r.lock.RLock()
+	sandboxed := !r.unsandboxed
+	r.lock.RUnlock()
+
+	for _, source := range r.sources {
+		entries, err := os.ReadDir(source.Directory)
+		if err != nil {
+			return fmt.Errorf("unable to read algorithm directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			// skip every entry that is a directory
+			if entry.IsDir() {
+				continue
+			}
+
+			// skip every entry that is not a supported script
+			if !slices.Contains(SupportedExtensions, strings.ToLower(filepath.Ext(entry.Name()))) {
+				continue
+			}
+
+			scriptPath := filepath.Join(source.Directory, entry.Name())
+			algorithm, err := loadAlgorithm(scriptPath, source.Origin, sandboxed)
+			if err != nil {
+				log.Warn().Err(err).Str("script", scriptPath).Msg("unable to load algorithm")
+				failures[scriptPath] = err
+				continue
+			}
+
+			if existing, isSet := algorithms[algorithm.Identifier]; isSet {
+				err = fmt.Errorf("identifier '%s' already provided by %s algorithm '%s'", algorithm.Identifier, existing.Origin, existing.Script)
+				log.Warn().Err(err).Str("script", scriptPath).Msg("unable to load algorithm")
+				failures[scriptPath] = err
+				continue
+			}
+			algorithms[algorithm.Identifier] = algorithm
+		}
+	}
+
+	r.lock.Lock()
+	for _, registration := range r.registrations {
+		metadata := registration.forecaster.Metadata()
+		metadata.Parameters = registration.forecaster.Parameters()
+		if err := validateMetadata(metadata); err != nil {
+			err = fmt.Errorf("invalid metadata: %w", err)
+			log.Warn().Err(err).Str("algorithm", registration.identifier).Msg("unable to load algorithm")
+			failures[registration.identifier] = err
+			continue
+		}
+
+		algorithm, isSet := algorithms[registration.identifier]
+		if isSet && algorithm.Origin != OriginInternal {
+			err := fmt.Errorf("identifier '%s' already provided by native algorithm", registration.identifier)
+			log.Warn().Err(err).Str("script", algorithm.Script).Msg("unable to load algorithm")
+			failures[algorithm.Script] = err
+			algorithm = Algorithm{}
+		}
+		algorithm.Identifier = registration.identifier
+		algorithm.Metadata = metadata
+		algorithm.Origin = OriginInternal
+		algorithm.Forecaster = registration.forecaster
+		algorithm.Checksum = "native"
+		algorithms[registration.identifier] = algorithm
+	}
+	r.algorithms = algorithms
+	r.failures = failures
+	r.lock.Unlock()
+
+	log.Info().Int("algorithms", len(algorithms)).Int("failures", len(failures)).Msg("loaded algorithms")
+	return nil
+}
+
+// Lookup returns the algorithm with the exact identifier supplied
+func (r *Registry) Lookup(identifier string) (Algorithm, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	algorithm, found := r.algorithms[identifier]
+	return algorithm, found
+}
+
+// Algorithms returns all loaded algorithms sorted by their identifier
+func (r *Registry) Algorithms() []Algorithm {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	algorithms := make([]Algorithm, 0, len(r.algorithms))
+	for _, algorithm := range r.algorithms {
+		algorithms = append(algorithms, algorithm)
+	}
+	slices.SortFunc(algorithms, func(a, b Algorithm) int {
+		return strings.Compare(a.Identifier, b.Identifier)
+	})
+	return algorithms
+}
+
+// Failures returns a copy of the errors that occurred during the last load
+// indexed by the file that caused them
+func (r *Registry) Failures() map[string]error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	failures := make(map[string]error, len(r.failures))
+	for file, err := range r.failures {
+		failures[file] = err
+	}
+	return failures
+}
+
+// loadAlgorithm reads the metadata belonging to the script and returns the
+// resulting algorithm
+func loadAlgorithm(scriptPath string, origin Origin, sandboxed bool) (Algorithm, error) {
+	directory, filename := filepath.Split(scriptPath)
+	identifier := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	metadataPath := filepath.Join(directory, identifier+metadataExtension)
+	metadata, err := helpers.GetAlgorithmMetadata(metadataPath)
+	if err != nil {
+		return Algorithm{}, fmt.Errorf("unable to load metadata: %w", err)
+	}
+	err = validateMetadata(metadata)
+	if err != nil {
+		return Algorithm{}, fmt.Errorf("invalid metadata: %w", err)
+	}
+	err = validateSandbox(metadata, origin)
+	if err != nil {
+		return Algorithm{}, fmt.Errorf("invalid metadata: %w", err)
+	}
+	var sandbox *helpers.Sandbox
+	if sandboxed {
+		// the configuration has already been validated
+		sandbox, _ = helpers.NewSandbox(metadata.Sandbox)
+	}
+
+	script, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return Algorithm{}, fmt.Errorf("unable to read script: %w", err)
+	}
+	checksum := sha256.Sum256(script)
+
+	return Algorithm{
+		Identifier: identifier,
+		Script:     scriptPath,
+		Forecaster: scriptForecaster{path: scriptPath, metadata: metadata, sandbox: sandbox},
+		Metadata:   metadata,
+		Origin:     origin,
+		Checksum:   hex.EncodeToString(checksum[:]),
+	}, nil
+}
+
+// validateMetadata checks the metadata for values that would prevent the
+// algorithm from being executed
+func validateMetadata(metadata types.AlgorithmMetadata) error {
+	if strings.TrimSpace(metadata.DisplayName) == "" {
+		return errors.New("no display name set")
+	}
+	if metadata.UseBuckets && strings.TrimSpace(metadata.BucketSize) == "" {
+		return errors.New("buckets enabled without a bucket size")
+	}
+	if metadata.UseBuckets {
+		if _, err := helpers.ParseInterval(metadata.BucketSize); err != nil {
+			return fmt.Errorf("invalid bucket size: %w", err)
+		}
+	}
+	if metadata.Timeout != "" {
+		timeout, err := time.ParseDuration(metadata.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		if timeout <= 0 {
+			return errors.New("timeout needs to be positive")
+		}
+	}
+	if metadata.MaxParallelExecutions < 0 {
+		return errors.New("maximal parallel executions may not be negative")
+	}
+	if _, err := helpers.NewSandbox(metadata.Sandbox); err != nil {
+		return fmt.Errorf("invalid sandbox: %w", err)
+	}
+	for name, parameter := range metadata.Parameters {
+		if !slices.Contains(parameterTypes, parameter.Type) {
+			return fmt.Errorf("parameter '%s' has unsupported type '%s', supported types are '%s'", name, parameter.Type, strings.Join(parameterTypes, "', '"))
+		}
+	}
+	return nil
+}
+
+// validateSandbox checks if the algorithm may use the sandbox configured in
+// its metadata. Only algorithms shipped with the service may disable the
+// sandbox, access the network, change the user or group executing them or
+// raise the limits above the defaults
+func validateSandbox(metadata types.AlgorithmMetadata, origin Origin) error {
+	if origin == OriginInternal {
+		return nil
+	}
+	configuration := metadata.Sandbox
+	switch {
+	case configuration.Disabled:
+		return fmt.Errorf("%s algorithms can not disable the sandbox", origin)
+	case configuration.Network:
+		return fmt.Errorf("%s algorithms can not access the network", origin)
+	case configuration.User != 0 && configuration.User != helpers.DefaultSandboxUser,
+		configuration.Group != 0 && configuration.Group != helpers.DefaultSandboxUser:
+		return fmt.Errorf("%s algorithms can not change the user or group executing them", origin)
+	}
+	sandbox, err := helpers.NewSandbox(configuration)
+	if err != nil {
+		return fmt.Errorf("invalid sandbox: %w", err)
+	}
+	switch {
+	case sandbox.CPUTime > helpers.DefaultSandboxCPUTime:
+		return fmt.Errorf("cpu time of %s algorithms may not exceed %s", origin, helpers.DefaultSandboxCPUTime)
+	case sandbox.Memory > helpers.DefaultSandboxMemory<<20:
+		return fmt.Errorf("memory of %s algorithms may not exceed %d MiB", origin, helpers.DefaultSandboxMemory)
+	case sandbox.OpenFiles > helpers.DefaultSandboxOpenFiles:
+		return fmt.Errorf("open files of %s algorithms may not exceed %d", origin, helpers.DefaultSandboxOpenFiles)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package registry
+
+// Origin describes where an algorithm has been loaded from
+type Origin string
+
+const (
+	// OriginInternal is used for algorithms shipped with the service
+	OriginInternal Origin = "internal"
+
+	// OriginExternal is used for algorithms which have been added to the
+	// service after it has been built
+	OriginExternal Origin = "external"
+
+	// OriginOnDemand is used for algorithms which are executed once without
+	// being added to the registry
+	OriginOnDemand Origin = "on-demand"
+)
+
+// Source describes a directory from which algorithms are loaded
+type Source struct {
+	// Directory contains the path to the directory containing the scripts and
+	// their metadata
+	Directory string
+
+	// Origin contains the origin assigned to every algorithm loaded from the
+	// directory
+	Origin Origin
+}
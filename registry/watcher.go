@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// metadataExtension contains the file extension used by the metadata files
+// accompanying the scripts
+const metadataExtension = ".yaml"
+
+// Watch watches the directories of the registry for changes to scripts and
+// metadata files and reloads the registry once no further changes occurred for
+// the duration supplied as delay.
+// Debouncing the changes prevents partially written files from being loaded
+// while a new algorithm is copied into a directory.
+// The watcher is stopped as soon as the context is cancelled.
+func (r *Registry) Watch(ctx context.Context, delay time.Duration) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to create file system watcher: %w", err)
+	}
+	for _, source := range r.sources {
+		err = watcher.Add(source.Directory)
+		if err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("unable to watch algorithm directory '%s': %w", source.Directory, err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		// the timer is created stopped and only started once a relevant change
+		// has been detected
+		reloadTimer := time.NewTimer(delay)
+		reloadTimer.Stop()
+		defer reloadTimer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, open := <-watcher.Events:
+				if !open {
+					return
+				}
+				if !relevantFile(event.Name) || event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+					continue
+				}
+				log.Debug().Str("file", event.Name).Str("operation", event.Op.String()).Msg("detected change in algorithm directory")
+				reloadTimer.Reset(delay)
+			case err, open := <-watcher.Errors:
+				if !open {
+					return
+				}
+				log.Warn().Err(err).Msg("error while watching algorithm directories")
+			case <-reloadTimer.C:
+				r.reload()
+			}
+		}
+	}()
+	return nil
+}
+
+// reload loads the algorithms again and reports the files which could not be
+// loaded. If the directories are not readable, the previously loaded
+// algorithms are kept.
+func (r *Registry) reload() {
+	log.Info().Msg("reloading algorithms after changes")
+	err := r.Load()
+	if err != nil {
+		log.Error().Err(err).Msg("unable to reload algorithms, keeping previous state")
+		return
+	}
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return
+	}
+	files := make([]string, 0, len(failures))
+	for file := range failures {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+	log.Warn().Strs("files", files).Msg("some algorithms failed validation after reload")
+}
+
+// relevantFile checks if the file is either a supported script or a metadata
+// file
+func relevantFile(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+	return extension == metadataExtension || slices.Contains(SupportedExtensions, extension)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/qustavo/dotsql"
+	"github.com/rs/zerolog/log"
+
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// UsageSelection describes the usage data that shall be pulled from the
+// database
+type UsageSelection struct {
+	// MunicipalKeys contains the keys of the municipals from which the water
+	// usages are used. Each key is used as a prefix for the selected area and
+	// needs to consist of digits only
+	MunicipalKeys []string
+
+	// ConsumerGroups contains the external identifiers of the consumer groups
+	// whose water usages are used. If no consumer groups are set, the usages
+	// of all consumer groups are used
+	ConsumerGroups []string
+
+	// UseBuckets specifies if the usages are aggregated into buckets
+	UseBuckets bool
+
+	// BucketSize contains the size of the buckets if UseBuckets is set
+	BucketSize pgtype.Interval
+
+	// From restricts the usage data to usages recorded at or after the time.
+	// It may be nil
+	From *time.Time
+
+	// Until restricts the usage data to usages recorded at or before the
+	// time. It may be nil
+	Until *time.Time
+}
+
+// Usages provides access to the water usages and consumer groups stored in
+// the database
+type Usages struct {
+	db      pgxscan.Querier
+	queries *dotsql.DotSql
+}
+
+// NewUsages creates a new repository executing the named queries on the
+// database
+func NewUsages(db pgxscan.Querier, queries *dotsql.DotSql) *Usages {
+	return &Usages{db: db, queries: queries}
+}
+
+// ConsumerGroups resolves the external identifiers of the consumer groups
+// into the ids used in the usage table
+func (u *Usages) ConsumerGroups(ctx context.Context, externalIdentifiers []string) ([]pgtype.UUID, error) {
+	query, err := u.queries.Raw("get-consumer-groups-by-external-id")
+	if err != nil {
+		return nil, err
+	}
+	var usageTypes []types.UsageType
+	err = pgxscan.Select(ctx, u.db, &usageTypes, query, externalIdentifiers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query usage types from database: %w", err)
+	}
+	ids := make([]pgtype.UUID, 0, len(usageTypes))
+	for _, usageType := range usageTypes {
+		ids = append(ids, usageType.ID)
+	}
+	return ids, nil
+}
+
+// Municipalities resolves the shapes and the GeoJSON geometry into the keys of
+// the municipalities lying within them. Only municipalities with recorded
+// usages are returned. The geometry may be nil
+func (u *Usages) Municipalities(ctx context.Context, shapes []int64, geometry json.RawMessage) ([]string, error) {
+	var municipalities []string
+	if len(shapes) > 0 {
+		query, err := u.queries.Raw("get-municipalities-by-shapes")
+		if err != nil {
+			return nil, err
+		}
+		var keys []string
+		err = pgxscan.Select(ctx, u.db, &keys, query, shapes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to query municipalities within shapes: %w", err)
+		}
+		municipalities = append(municipalities, keys...)
+	}
+	if len(geometry) > 0 {
+		query, err := u.queries.Raw("get-municipalities-by-geometry")
+		if err != nil {
+			return nil, err
+		}
+		var keys []string
+		err = pgxscan.Select(ctx, u.db, &keys, query, string(geometry))
+		if err != nil {
+			return nil, fmt.Errorf("unable to query municipalities within geometry: %w", err)
+		}
+		municipalities = append(municipalities, keys...)
+	}
+	slices.Sort(municipalities)
+	return slices.Compact(municipalities), nil
+}
+
+// Select pulls the usage data matching the selection from the database
+func (u *Usages) Select(ctx context.Context, selection UsageSelection) ([]types.UsageDataPoint, error) {
+	consumerGroupsSet := len(selection.ConsumerGroups) > 0
+	var consumerGroups []pgtype.UUID
+	if consumerGroupsSet {
+		var err error
+		consumerGroups, err = u.ConsumerGroups(ctx, selection.ConsumerGroups)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Debug().Msg("pulling usage data from the database")
+	var queryName string
+	var args []interface{}
+
+	// the keys are matched as prefixes of the municipalities using the
+	// starts-with operator, which is able to use the sp-gist index created by
+	// the migration in the migrations directory
+	switch {
+	case selection.UseBuckets && consumerGroupsSet:
+		queryName = "get-bucketed-usages-by-municipality-consumer-groups"
+		args = []interface{}{selection.BucketSize, selection.MunicipalKeys, consumerGroups}
+	case selection.UseBuckets && !consumerGroupsSet:
+		queryName = "get-bucketed-usages-by-municipality"
+		args = []interface{}{selection.BucketSize, selection.MunicipalKeys}
+	case !selection.UseBuckets && consumerGroupsSet:
+		queryName = "get-usages-by-municipality-consumer-groups"
+		args = []interface{}{selection.MunicipalKeys, consumerGroups}
+	case !selection.UseBuckets && !consumerGroupsSet:
+		queryName = "get-usages-by-municipality"
+		args = []interface{}{selection.MunicipalKeys}
+	}
+
+	// the time range is selected using dedicated queries to keep the
+	// conditions on the time simple enough to use the indexes of the
+	// hypertable. bounds which are not set are replaced by infinite
+	// timestamps
+	if selection.From != nil || selection.Until != nil {
+		queryName += "-time-range"
+		from := pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true}
+		if selection.From != nil {
+			from = pgtype.Timestamptz{Time: *selection.From, Valid: true}
+		}
+		until := pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true}
+		if selection.Until != nil {
+			until = pgtype.Timestamptz{Time: *selection.Until, Valid: true}
+		}
+		args = append(args, from, until)
+	}
+
+	query, err := u.queries.Raw(queryName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare query for usage data: %w", err)
+	}
+
+	var usageDataPoints []types.UsageDataPoint
+	err = pgxscan.Select(ctx, u.db, &usageDataPoints, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query usage data from database: %w", err)
+	}
+	log.Debug().Int("dataPoints", len(usageDataPoints)).Msg("pulled usage data from the database")
+	return usageDataPoints, nil
+}
@@ -13,13 +13,32 @@ SELECT id,
 FROM wisdom.water_usage.usage_types
 WHERE external_identifier = ANY ($1);
 
+-- name: get-municipalities-by-shapes
+SELECT DISTINCT municipality.key
+FROM wisdom.geodata.shapes municipality,
+    wisdom.geodata.shapes selection
+WHERE selection.id = ANY ($1)
+  AND ST_Within(ST_PointOnSurface(municipality.geometry), selection.geometry)
+  AND EXISTS (SELECT 1
+              FROM wisdom.timeseries.water_usage
+              WHERE water_usage.municipality = municipality.key);
+
+-- name: get-municipalities-by-geometry
+SELECT DISTINCT municipality.key
+FROM wisdom.geodata.shapes municipality
+WHERE ST_Within(ST_PointOnSurface(municipality.geometry),
+                ST_Transform(ST_SetSRID(ST_GeomFromGeoJSON($1), 4326), ST_SRID(municipality.geometry)))
+  AND EXISTS (SELECT 1
+              FROM wisdom.timeseries.water_usage
+              WHERE water_usage.municipality = municipality.key);
+
 -- name: get-usages-by-municipality
 SELECT municipality,
     time,
     usage_type,
     amount
 FROM wisdom.timeseries.water_usage
-WHERE municipality ~ $1;
+WHERE municipality ^@ ANY ($1);
 
 -- name: get-usages-by-municipality-consumer-groups
 SELECT municipality,
@@ -27,26 +46,137 @@ SELECT municipality,
     usage_type,
     amount
 FROM wisdom.timeseries.water_usage
-WHERE municipality ~ $1
-  AND usage_type IN ($2);
+WHERE municipality ^@ ANY ($1)
+  AND usage_type = ANY ($2);
 
 -- name: get-bucketed-usages-by-municipality
 SELECT municipality,
-    time_bucket($1, time) AS time,
+    time_bucket($1::interval, time) AS time,
     usage_type,
     SUM(amount)           AS amount
 FROM wisdom.timeseries.water_usage
-WHERE municipality ~ $2
-GROUP BY time, municipality, usage_type
+WHERE municipality ^@ ANY ($2)
+GROUP BY time_bucket($1::interval, time), municipality, usage_type
 ORDER BY time;
 
 -- name: get-bucketed-usages-by-municipality-consumer-groups
 SELECT municipality,
-    time_bucket($1, time) AS time,
+    time_bucket($1::interval, time) AS time,
+    usage_type,
+    SUM(amount)           AS amount
+FROM wisdom.timeseries.water_usage
+WHERE municipality ^@ ANY ($2)
+  AND usage_type = ANY ($3)
+GROUP BY time_bucket($1::interval, time), municipality, usage_type
+ORDER BY time;
+
+-- name: get-usages-by-municipality-time-range
+SELECT municipality,
+    time,
+    usage_type,
+    amount
+FROM wisdom.timeseries.water_usage
+WHERE municipality ^@ ANY ($1)
+  AND time >= $2
+  AND time <= $3;
+
+-- name: get-usages-by-municipality-consumer-groups-time-range
+SELECT municipality,
+    time,
+    usage_type,
+    amount
+FROM wisdom.timeseries.water_usage
+WHERE municipality ^@ ANY ($1)
+  AND usage_type = ANY ($2)
+  AND time >= $3
+  AND time <= $4;
+
+-- name: get-bucketed-usages-by-municipality-time-range
+SELECT municipality,
+    time_bucket($1::interval, time) AS time,
+    usage_type,
+    SUM(amount)           AS amount
+FROM wisdom.timeseries.water_usage
+WHERE municipality ^@ ANY ($2)
+  AND time >= $3
+  AND time <= $4
+GROUP BY time_bucket($1::interval, time), municipality, usage_type
+ORDER BY time;
+
+-- name: get-bucketed-usages-by-municipality-consumer-groups-time-range
+SELECT municipality,
+    time_bucket($1::interval, time) AS time,
     usage_type,
     SUM(amount)           AS amount
 FROM wisdom.timeseries.water_usage
-WHERE municipality ~ $2
-  AND usage_type IN ($3)
-GROUP BY time, municipality, usage_type
-ORDER BY time;
\ No newline at end of file
+WHERE municipality ^@ ANY ($2)
+  AND usage_type = ANY ($3)
+  AND time >= $4
+  AND time <= $5
+GROUP BY time_bucket($1::interval, time), municipality, usage_type
+ORDER BY time;
+
+-- name: create-forecasts-schema
+CREATE SCHEMA IF NOT EXISTS forecasts;
+
+-- name: create-runs-table
+CREATE TABLE IF NOT EXISTS wisdom.forecasts.runs
+(
+    id                uuid PRIMARY KEY     DEFAULT gen_random_uuid(),
+    algorithm         text        NOT NULL,
+    parameters        jsonb,
+    municipality_keys text[]      NOT NULL,
+    consumer_groups   text[]      NOT NULL DEFAULT '{}',
+    data_from         timestamptz,
+    data_until        timestamptz,
+    output            jsonb,
+    duration          interval    NOT NULL,
+    status            text        NOT NULL,
+    exit_code         integer,
+    signal            text,
+    error             text,
+    created_at        timestamptz NOT NULL DEFAULT now()
+);
+
+-- name: insert-run
+INSERT INTO wisdom.forecasts.runs (algorithm, parameters, municipality_keys, consumer_groups, data_from, data_until,
+                                   output, duration, status, exit_code, signal, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id;
+
+-- name: get-runs
+SELECT id,
+    algorithm,
+    parameters,
+    municipality_keys,
+    consumer_groups,
+    data_from,
+    data_until,
+    extract(EPOCH FROM duration)::double precision AS duration,
+    status,
+    exit_code,
+    signal,
+    error,
+    created_at
+FROM wisdom.forecasts.runs
+WHERE ($1::text IS NULL OR algorithm = $1)
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: get-run-by-id
+SELECT id,
+    algorithm,
+    parameters,
+    municipality_keys,
+    consumer_groups,
+    data_from,
+    data_until,
+    output,
+    extract(EPOCH FROM duration)::double precision AS duration,
+    status,
+    exit_code,
+    signal,
+    error,
+    created_at
+FROM wisdom.forecasts.runs
+WHERE id = $1;
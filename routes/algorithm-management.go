@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	wisdomMiddlware "github.com/wisdom-oss/microservice-middlewares/v4"
+
+	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+	"github.com/wisdom-oss/service-usage-forecasts/registry"
+)
+
+// maxUploadSize contains the maximum size of the request body used for
+// uploading an algorithm
+const maxUploadSize = 5242880
+
+// ErrMissingUploadField is an error that occurs when the multipart body used
+// to upload an algorithm is missing one of the required fields.
+var ErrMissingUploadField = problems.New("MISSING_UPLOAD_FIELD")
+
+// ErrInvalidAlgorithmUpload is an error that occurs when the uploaded script
+// or metadata did not pass the validation.
+var ErrInvalidAlgorithmUpload = problems.New("INVALID_ALGORITHM_UPLOAD")
+
+// ErrAlgorithmExists is an error that occurs when an algorithm is uploaded
+// using an identifier which is already in use.
+var ErrAlgorithmExists = problems.New("ALGORITHM_EXISTS")
+
+// ErrInternalAlgorithm is an error that occurs when an algorithm shipped with
+// the service shall be replaced or removed.
+var ErrInternalAlgorithm = problems.New("INTERNAL_ALGORITHM")
+
+// UploadAlgorithm stores a new algorithm in the external algorithm location.
+// The algorithm is supplied as multipart body containing the identifier, the
+// script and the metadata file.
+func UploadAlgorithm(w http.ResponseWriter, r *http.Request) {
+	storeAlgorithm(w, r, false)
+}
+
+// ReplaceAlgorithm stores an algorithm in the external algorithm location and
+// replaces an already existing external algorithm with the same identifier.
+func ReplaceAlgorithm(w http.ResponseWriter, r *http.Request) {
+	storeAlgorithm(w, r, true)
+}
+
+// DeleteAlgorithm removes an algorithm from the external algorithm location.
+func DeleteAlgorithm(w http.ResponseWriter, r *http.Request) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	identifier := strings.TrimSpace(chi.URLParam(r, "algorithm-name"))
+	err := globals.Algorithms.Remove(identifier)
+	switch {
+	case errors.Is(err, registry.ErrUnknownAlgorithm):
+		errorHandler <- ErrUnknownAlgorithm
+		<-statusChannel
+		return
+	case errors.Is(err, registry.ErrInternalAlgorithm):
+		errorHandler <- ErrInternalAlgorithm
+		<-statusChannel
+		return
+	case err != nil:
+		errorHandler <- fmt.Errorf("unable to remove algorithm: %w", err)
+		<-statusChannel
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// storeAlgorithm reads the script and metadata from the multipart body and
+// installs them into the algorithm registry. If an algorithm is replaced, the
+// identifier is taken from the url, otherwise it is read from the body
+func storeAlgorithm(w http.ResponseWriter, r *http.Request, replace bool) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	err := r.ParseMultipartForm(maxUploadSize)
+	if errors.Is(err, http.ErrNotMultipart) {
+		e := ErrUnsupportedContentType
+		e.Error = "uploading algorithms requires a multipart/form-data body"
+		errorHandler <- e
+		<-statusChannel
+		return
+	}
+	if err != nil {
+		e := ErrInvalidRequestBody
+		e.Error = err.Error()
+		errorHandler <- e
+		<-statusChannel
+		return
+	}
+
+	var identifier string
+	if replace {
+		identifier = strings.TrimSpace(chi.URLParam(r, "algorithm-name"))
+	} else {
+		identifier = strings.TrimSpace(r.FormValue("identifier"))
+	}
+	if identifier == "" {
+		errorHandler <- ErrMissingUploadField
+		<-statusChannel
+		return
+	}
+
+	scriptFile, scriptHeader, err := r.FormFile("script")
+	if err != nil {
+		errorHandler <- ErrMissingUploadField
+		<-statusChannel
+		return
+	}
+	defer scriptFile.Close()
+	script, err := io.ReadAll(scriptFile)
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable to read uploaded script: %w", err)
+		<-statusChannel
+		return
+	}
+
+	metadata, err := readMetadataField(r)
+	if err != nil {
+		errorHandler <- ErrMissingUploadField
+		<-statusChannel
+		return
+	}
+
+	algorithm, err := globals.Algorithms.Install(identifier, scriptHeader.Filename, script, metadata, replace)
+	switch {
+	case errors.Is(err, registry.ErrInvalidAlgorithm):
+		e := ErrInvalidAlgorithmUpload
+		e.Error = err.Error()
+		errorHandler <- e
+		<-statusChannel
+		return
+	case errors.Is(err, registry.ErrAlgorithmExists):
+		errorHandler <- ErrAlgorithmExists
+		<-statusChannel
+		return
+	case errors.Is(err, registry.ErrInternalAlgorithm):
+		errorHandler <- ErrInternalAlgorithm
+		<-statusChannel
+		return
+	case err != nil:
+		errorHandler <- fmt.Errorf("unable to store algorithm: %w", err)
+		<-statusChannel
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if replace {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	err = json.NewEncoder(w).Encode(algorithm.Information())
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable encode response: %w", err)
+		<-statusChannel
+		return
+	}
+}
+
+// readMetadataField reads the metadata from the multipart body. The metadata
+// may either be uploaded as file or as plain form value
+func readMetadataField(r *http.Request) ([]byte, error) {
+	file, _, err := r.FormFile("metadata")
+	if err == nil {
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+	if values := r.MultipartForm.Value["metadata"]; len(values) > 0 {
+		return []byte(values[0]), nil
+	}
+	return nil, err
+}
@@ -0,0 +1,93 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+)
+
+// newTestRouter creates a router serving the routes used to store algorithms
+// with the error handler of the service
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	catalogue, err := problems.Load("../resources/errors.json")
+	if err != nil {
+		t.Fatalf("unable to load error catalogue: %v", err)
+	}
+	router := chi.NewRouter()
+	router.Use(problems.ErrorHandler(catalogue))
+	router.Post("/", UploadAlgorithm)
+	router.Put("/{algorithm-name}", ReplaceAlgorithm)
+	return router
+}
+
+func TestStoreAlgorithmRejectsInvalidBodies(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+	}{
+		{
+			name:        "json body",
+			contentType: "application/json",
+			body:        `{"identifier": "linear"}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantCode:    ErrUnsupportedContentType.Code,
+		},
+		{
+			name:       "missing content type",
+			body:       "identifier=linear",
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantCode:   ErrUnsupportedContentType.Code,
+		},
+		{
+			name:        "malformed multipart body",
+			contentType: "multipart/form-data; boundary=boundary",
+			body:        "--boundary\r\nContent-Disposition: form-data; name=\"identifier\"\r\n\r\nlinear",
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    ErrInvalidRequestBody.Code,
+		},
+		{
+			name:        "oversized body",
+			contentType: "multipart/form-data; boundary=boundary",
+			body: "--boundary\r\nContent-Disposition: form-data; name=\"script\"; filename=\"linear.py\"\r\n\r\n" +
+				strings.Repeat("#", maxUploadSize+1) + "\r\n--boundary--\r\n",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ErrInvalidRequestBody.Code,
+		},
+	}
+
+	for _, test := range tests {
+		for _, route := range []struct{ method, path string }{{http.MethodPost, "/"}, {http.MethodPut, "/linear"}} {
+			t.Run(test.name+" "+route.method, func(t *testing.T) {
+				r := httptest.NewRequest(route.method, route.path, strings.NewReader(test.body))
+				if test.contentType != "" {
+					r.Header.Set("Content-Type", test.contentType)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+
+				if w.Code != test.wantStatus {
+					t.Errorf("expected status %d, got %d", test.wantStatus, w.Code)
+				}
+				var response problems.Response
+				if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+					t.Fatalf("unable to decode response: %v", err)
+				}
+				if response.Code != test.wantCode {
+					t.Errorf("expected code %s, got %s", test.wantCode, response.Code)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+)
+
+// ErrInvalidArea is an error that occurs when the shapes or the geometry used
+// to select the area are invalid.
+var ErrInvalidArea = problems.New("INVALID_AREA")
+
+// ErrEmptyArea is an error that occurs when the shapes or the geometry used to
+// select the area do not contain any municipality with recorded water usages.
+var ErrEmptyArea = problems.New("EMPTY_AREA")
+
+// parseShapes parses the ids of the shapes supplied as query parameters
+func parseShapes(values []string) ([]int64, error) {
+	shapes := make([]int64, 0, len(values))
+	for _, value := range values {
+		shape, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shape id '%s'", value)
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes, nil
+}
+
+// validateGeometry checks that the geometry is a GeoJSON polygon or
+// multipolygon. The coordinates are validated by the database while resolving
+// the geometry
+func validateGeometry(geometry json.RawMessage) error {
+	var object struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(geometry, &object); err != nil {
+		return fmt.Errorf("geometry is not a GeoJSON object: %w", err)
+	}
+	if object.Type != "Polygon" && object.Type != "MultiPolygon" {
+		return fmt.Errorf("unsupported geometry type '%s'", object.Type)
+	}
+	var coordinates []json.RawMessage
+	if err := json.Unmarshal(object.Coordinates, &coordinates); err != nil || len(coordinates) == 0 {
+		return errors.New("geometry does not contain any coordinates")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	wisdomMiddlware "github.com/wisdom-oss/microservice-middlewares/v4"
+
+	"github.com/wisdom-oss/service-usage-forecasts/globals"
+)
+
+// ErrorCatalogue lists the errors the service may respond with together with
+// their codes and http status codes
+func ErrorCatalogue(w http.ResponseWriter, r *http.Request) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(globals.Errors.Definitions())
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable encode response: %w", err)
+		<-statusChannel
+		return
+	}
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	wisdomMiddlware "github.com/wisdom-oss/microservice-middlewares/v4"
+
+	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// InvalidAlgorithms lists the scripts which failed the validation during the
+// last (re)load of the algorithm registry together with the reason
+func InvalidAlgorithms(w http.ResponseWriter, r *http.Request) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	failures := make([]types.AlgorithmFailure, 0)
+	for file, err := range globals.Algorithms.Failures() {
+		failures = append(failures, types.AlgorithmFailure{
+			File:  file,
+			Error: err.Error(),
+		})
+	}
+	slices.SortFunc(failures, func(a, b types.AlgorithmFailure) int {
+		return strings.Compare(a.File, b.File)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(failures)
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable encode response: %w", err)
+		<-statusChannel
+		return
+	}
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	wisdomMiddlware "github.com/wisdom-oss/microservice-middlewares/v4"
+
+	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/pipeline"
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+)
+
+// ErrUnknownJob is an error that occurs when the job requested does not exist
+// or has already been removed after its retention time.
+var ErrUnknownJob = problems.New("UNKNOWN_JOB")
+
+// SubmitForecastJob places a forecast into the queue and returns the job
+// which allows polling its status and result
+func SubmitForecastJob(w http.ResponseWriter, r *http.Request) {
+	request, ok := parseForecastRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// the forecast is placed into the queue of the scheduler before responding
+	// to reject the job immediately if the queue is full. the place is given
+	// up by the pipeline if the output is cached
+	ticket, err := globals.Scheduler.Enqueue(request.Algorithm.Identifier, request.Algorithm.Metadata.MaxParallelExecutions)
+	if err != nil {
+		sendForecastError(w, r, err)
+		return
+	}
+
+	// the id of the request is passed on to identify the logs of the job
+	requestID := chiMiddleware.GetReqID(r.Context())
+	job := globals.Jobs.Submit(request.Algorithm.Identifier, func(ctx context.Context, started func()) ([]byte, error) {
+		ctx = context.WithValue(ctx, chiMiddleware.RequestIDKey, requestID)
+		request.Started = started
+		request.Ticket = ticket
+		result, err := pipeline.Run(ctx, request)
+		return result.Output, err
+	})
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJob(w, r, http.StatusAccepted, job)
+}
+
+// GetForecastJob returns the current status of a job and its result once the
+// job succeeded
+func GetForecastJob(w http.ResponseWriter, r *http.Request) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	job, found := globals.Jobs.Get(chi.URLParam(r, "job-id"))
+	if !found {
+		errorHandler <- ErrUnknownJob
+		<-statusChannel
+		return
+	}
+	writeJob(w, r, http.StatusOK, job)
+}
+
+// CancelForecastJob cancels a job that has not finished yet. Finished jobs are
+// removed instead
+func CancelForecastJob(w http.ResponseWriter, r *http.Request) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	job, found := globals.Jobs.Cancel(chi.URLParam(r, "job-id"))
+	if !found {
+		errorHandler <- ErrUnknownJob
+		<-statusChannel
+		return
+	}
+	writeJob(w, r, http.StatusOK, job)
+}
+
+// writeJob sends the job as response using the supplied status code
+func writeJob(w http.ResponseWriter, r *http.Request, status int, job interface{}) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(job)
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable encode response: %w", err)
+		<-statusChannel
+		return
+	}
+}
@@ -0,0 +1,104 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	wisdomMiddlware "github.com/wisdom-oss/microservice-middlewares/v4"
+
+	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/pipeline"
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+	"github.com/wisdom-oss/service-usage-forecasts/registry"
+)
+
+// ErrOnDemandUnavailable is an error that occurs when an on-demand forecast is
+// requested, but the service is unable to execute scripts in a sandbox.
+var ErrOnDemandUnavailable = problems.New("ON_DEMAND_UNAVAILABLE")
+
+// ErrMissingScript is an error that occurs when the multipart body of an
+// on-demand forecast does not contain the script.
+var ErrMissingScript = problems.New("MISSING_SCRIPT")
+
+// OnDemandForecast executes a script supplied in the request once and returns
+// its output. The script is supplied as multipart body together with the
+// optional metadata and parameters, while the area and consumer groups are
+// selected using the query parameters like for predefined forecasts.
+// The script is executed in a strict sandbox and removed after the forecast.
+func OnDemandForecast(w http.ResponseWriter, r *http.Request) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	err := r.ParseMultipartForm(maxUploadSize)
+	if errors.Is(err, http.ErrNotMultipart) {
+		e := ErrUnsupportedContentType
+		e.Error = "on-demand forecasts require a multipart/form-data body"
+		errorHandler <- e
+		<-statusChannel
+		return
+	}
+	if err != nil {
+		e := ErrInvalidRequestBody
+		e.Error = err.Error()
+		errorHandler <- e
+		<-statusChannel
+		return
+	}
+
+	scriptFile, scriptHeader, err := r.FormFile("script")
+	if err != nil {
+		errorHandler <- ErrMissingScript
+		<-statusChannel
+		return
+	}
+	defer scriptFile.Close()
+	script, err := io.ReadAll(scriptFile)
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable to read uploaded script: %w", err)
+		<-statusChannel
+		return
+	}
+
+	// the metadata is optional for on-demand forecasts
+	metadata, err := readMetadataField(r)
+	if err != nil && !errors.Is(err, http.ErrMissingFile) {
+		errorHandler <- fmt.Errorf("unable to read uploaded metadata: %w", err)
+		<-statusChannel
+		return
+	}
+
+	algorithm, remove, err := globals.Algorithms.OnDemand(scriptHeader.Filename, script, metadata, globals.OnDemandTimeout)
+	switch {
+	case errors.Is(err, registry.ErrSandboxUnavailable):
+		errorHandler <- ErrOnDemandUnavailable
+		<-statusChannel
+		return
+	case errors.Is(err, registry.ErrInvalidAlgorithm):
+		e := ErrInvalidAlgorithmUpload
+		e.Error = err.Error()
+		errorHandler <- e
+		<-statusChannel
+		return
+	case err != nil:
+		errorHandler <- fmt.Errorf("unable to prepare on-demand algorithm: %w", err)
+		<-statusChannel
+		return
+	}
+	defer remove()
+
+	request, ok := prepareForecastRequest(w, r, algorithm)
+	if !ok {
+		return
+	}
+
+	result, err := pipeline.Run(r.Context(), request)
+	if err != nil {
+		sendForecastError(w, r, err)
+		return
+	}
+	sendForecastResult(w, r, result)
+}
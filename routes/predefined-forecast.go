@@ -1,304 +1,446 @@
 package routes
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"mime"
 	"net/http"
-	"os"
+	"regexp"
 	"strings"
+	"time"
 
-	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
-	wisdomType "github.com/wisdom-oss/commonTypes/v2"
 	wisdomMiddlware "github.com/wisdom-oss/microservice-middlewares/v4"
-	"gopkg.in/yaml.v3"
 
 	"github.com/wisdom-oss/service-usage-forecasts/globals"
 	"github.com/wisdom-oss/service-usage-forecasts/helpers"
+	"github.com/wisdom-oss/service-usage-forecasts/native"
+	"github.com/wisdom-oss/service-usage-forecasts/pipeline"
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+	"github.com/wisdom-oss/service-usage-forecasts/registry"
+	"github.com/wisdom-oss/service-usage-forecasts/scheduler"
 	"github.com/wisdom-oss/service-usage-forecasts/types"
 )
 
 // ErrNoAreaSelected is an error that occurs when the request did not specify
 // the area for which the prognosis shall be executed.
-var ErrNoAreaSelected = wisdomType.WISdoMError{
-	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
-	Status: http.StatusBadRequest,
-	Title:  "No Area Selected",
-	Detail: "The request did not specify the area for which the prognosis shall be executed, this is not allowed",
-}
+var ErrNoAreaSelected = problems.New("NO_AREA_DEFINED")
+
+// ErrInvalidMunicipalKey is an error that occurs when a key used to select the
+// area is not a valid prefix of an official municipality key.
+var ErrInvalidMunicipalKey = problems.New("INVALID_MUNICIPAL_KEY")
+
+// municipalKeyPattern describes the accepted keys for selecting the area. A
+// key is a prefix of the regional key (ARS) of a municipality
+var municipalKeyPattern = regexp.MustCompile(`^[0-9]{1,12}$`)
 
 // ErrNoAlgorithmSpecified is an error that occurs when the request did not
 // contain an identifier for an algorithm.
-var ErrNoAlgorithmSpecified = wisdomType.WISdoMError{
-	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
-	Status: http.StatusBadRequest,
-	Title:  "No Algorithm Specified",
-	Detail: "The request did not contain a identifier for an algorithm",
-}
+var ErrNoAlgorithmSpecified = problems.New("ALGORITHM_NOT_SET")
 
 // ErrUnknownAlgorithm is an error that occurs when the algorithm specified in
 // the request does not exist on the server.
 // Please check your request and make sure that the requested script is stored
 // on the server
-var ErrUnknownAlgorithm = wisdomType.WISdoMError{
-	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.5",
-	Status: http.StatusNotFound,
-	Title:  "Unknown Algorithm",
-	Detail: "The algorithm specified in the request does not exist on the server. Please check your request and make sure that the requested script is stored on the server",
-}
+var ErrUnknownAlgorithm = problems.New("UNKNOWN_ALGORITHM")
 
-var ErrInvalidBucketSize = wisdomType.WISdoMError{
-	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
-	Status: http.StatusBadRequest,
-	Title:  "Invalid Bucket Size",
-	Detail: "The amount of seconds provided for the size of the bucket is not valid. Please check the documentation",
-}
+// ErrForecastTimedOut is an error that occurs when the algorithm did not
+// finish within the timeout configured for the algorithm.
+var ErrForecastTimedOut = problems.New("FORECAST_TIMED_OUT")
+
+// ErrServiceBusy is an error that occurs when too many forecasts are already
+// waiting for their execution.
+var ErrServiceBusy = problems.New("SERVICE_BUSY")
+
+// ErrInvalidTimeRange is an error that occurs when the time range restricting
+// the usage data is invalid.
+var ErrInvalidTimeRange = problems.New("INVALID_TIME_RANGE")
+
+// ErrUnsupportedContentType is an error that occurs when the body of a forecast
+// request uses a content type which is not supported.
+var ErrUnsupportedContentType = problems.New("UNSUPPORTED_CONTENT_TYPE")
+
+// ErrInvalidRequestBody is an error that occurs when the body of a forecast
+// request could not be parsed.
+var ErrInvalidRequestBody = problems.New("INVALID_REQUEST_BODY")
+
+// ErrInvalidAlgorithmOutput is an error that occurs when the algorithm
+// finished but its output does not match the schema of the forecast results.
+var ErrInvalidAlgorithmOutput = problems.New("INVALID_ALGORITHM_OUTPUT")
+
+// ErrAlgorithmFailed is an error that occurs when the algorithm exited
+// unsuccessfully or has been terminated by a signal.
+var ErrAlgorithmFailed = problems.New("ALGORITHM_FAILED")
+
+// ErrUnsuitableUsageData is an error that occurs when a native algorithm is
+// unable to calculate a forecast from the usage data of the selected area,
+// e.g., since the data series are too short.
+var ErrUnsuitableUsageData = problems.New("UNSUITABLE_USAGE_DATA")
+
+// ErrSandboxUnavailable is an error that occurs when the sandbox of the
+// algorithm could not be set up, e.g., since the service lacks the privileges
+// to create namespaces.
+var ErrSandboxUnavailable = problems.New("SANDBOX_UNAVAILABLE")
+
+// maxBodySize contains the maximum size of the body of a forecast request
+const maxBodySize = 5242880
+
+// retryAfter contains the number of seconds a client is asked to wait before
+// retrying a forecast that has been rejected due to a full queue
+const retryAfter = "30"
+
+// ErrInvalidParameters is an error that occurs when the parameters supplied
+// for the forecast do not match the parameters of the algorithm.
+var ErrInvalidParameters = problems.New("INVALID_PARAMETERS")
+
+// ErrInvalidBucketSize is an error that occurs when the size of the buckets
+// requested for the forecast is not valid or may not be changed for the
+// algorithm.
+var ErrInvalidBucketSize = problems.New("INVALID_BUCKET_SIZE")
 
 // PredefinedForecast handles requests for predefined forecasts.
-// this also includes external predefined forecast algorithms loaded during the
-// startup
+// this also includes the external predefined forecast algorithms loaded into
+// the algorithm registry
 func PredefinedForecast(w http.ResponseWriter, r *http.Request) {
-	// access the error handlers
-	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
-	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+	request, ok := parseForecastRequest(w, r)
+	if !ok {
+		return
+	}
 
-	// get the municipals identifying the regions from which the water usages
-	// shall be taken
-	municipalKeys, keysSet := r.URL.Query()["key"]
-	if !keysSet {
-		errorHandler <- ErrNoAreaSelected
-		<-statusChannel
+	// the pipeline places the forecast into the queue of the scheduler unless
+	// the output is cached
+	result, err := pipeline.Run(r.Context(), request)
+	if err != nil {
+		sendForecastError(w, r, err)
 		return
 	}
+	sendForecastResult(w, r, result)
+}
+
+// sendForecastResult sends the output of the algorithm to the client
+func sendForecastResult(w http.ResponseWriter, r *http.Request, result pipeline.Result) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
 
-	// now create a regular expression for the municipalites
-	var keyRegEx string
-	for _, key := range municipalKeys {
-		keyRegEx += fmt.Sprintf(`^%s\d*$|`, key)
+	// now send the output of the algorithm directly back to the client and
+	// reference the run recorded in the history
+	if result.RunID != "" {
+		w.Header().Set("X-Forecast-Run", result.RunID)
 	}
-	keyRegEx = strings.Trim(keyRegEx, "|")
-
-	// now get the consumer groups from the query parameters
-	consumerGroups, consumerGroupsSet := r.URL.Query()["consumerGroup"]
-	if consumerGroupsSet {
-		// since there are consumer groups set, resolve the external identifiers
-		// into the uuids that are used in the usage table
-		query, err := globals.SqlQueries.Raw("get-consumer-groups-by-external-id")
-		if err != nil {
-			errorHandler <- err
-			<-statusChannel
-			return
-		}
-		// now get the usage type ids
-		var usageTypes []types.UsageType
-		err = pgxscan.Select(r.Context(), globals.Db, &usageTypes, query, consumerGroups)
-		if err != nil {
-			errorHandler <- fmt.Errorf("unable to query usage types from database: %w", err)
-			<-statusChannel
-			return
-		}
-		var consumerGroupIDs []string
-		for _, usageType := range usageTypes {
-			uuid, _ := usageType.ID.Value()
-			consumerGroupIDs = append(consumerGroupIDs, uuid.(string))
-		}
-		// now reassign the resolved consumer group ids to the consumer groups
-		consumerGroups = consumerGroupIDs
+	if result.Cached {
+		w.Header().Set("X-Cache", "HIT")
 	} else {
-		query, err := globals.SqlQueries.Raw("get-consumer-groups")
-		if err != nil {
-			errorHandler <- err
-			<-statusChannel
-			return
-		}
-		var usageTypes []types.UsageType
-		err = pgxscan.Select(r.Context(), globals.Db, &usageTypes, query)
-		if err != nil {
-			errorHandler <- fmt.Errorf("unable to query usage types from database: %w", err)
-			<-statusChannel
-			return
-		}
-		var consumerGroupIDs []string
-		for _, usageType := range usageTypes {
-			uuid, _ := usageType.ID.Value()
-			consumerGroupIDs = append(consumerGroupIDs, uuid.(string))
-		}
-		// now reassign the resolved consumer group ids to the consumer groups
-		consumerGroups = consumerGroupIDs
+		w.Header().Set("X-Cache", "MISS")
 	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err := w.Write(result.Output)
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable to send results: %w", err)
+		<-statusChannel
+		return
+	}
+}
 
-	// since now all required data sets are available for getting the usage data,
-	// validate that the algorithm selected is even present on the service
+// parseForecastRequest reads the algorithm, the selected area, the consumer
+// groups and the parameters from the request. If the request is invalid, the
+// error is sent to the error handler and false is returned
+func parseForecastRequest(w http.ResponseWriter, r *http.Request) (pipeline.Request, bool) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
 
 	// get the algorithm from the url parameters
 	algorithmName := strings.TrimSpace(chi.URLParam(r, "algorithm-name"))
 	if algorithmName == "" {
 		errorHandler <- ErrNoAlgorithmSpecified
 		<-statusChannel
-		return
+		return pipeline.Request{}, false
 	}
 
-	// get all entries of the directory in which the algorithms are stored
-	var algorithmFileName string
-	entries, err := os.ReadDir(globals.Environment["INTERNAL_ALGORITHM_LOCATION"])
-	if err != nil {
-		errorHandler <- err
+	// now look up the algorithm in the registry
+	algorithm, found := globals.Algorithms.Lookup(algorithmName)
+	if !found {
+		errorHandler <- ErrUnknownAlgorithm
 		<-statusChannel
-		return
+		return pipeline.Request{}, false
 	}
-	// now iterate over the entries
-	for _, entry := range entries {
-		// skip every entry that is a directory
-		if entry.IsDir() {
-			continue
-		}
+	return prepareForecastRequest(w, r, algorithm)
+}
 
-		// now check if the file name starts with the algorithm name and skip
-		// every entry that does not start with the algorithm name
-		if !strings.HasPrefix(entry.Name(), algorithmName) {
-			continue
-		}
+// prepareForecastRequest reads the selected area, the consumer groups and the
+// parameters for the algorithm from the request. If the request is invalid,
+// the error is sent to the error handler and false is returned
+func prepareForecastRequest(w http.ResponseWriter, r *http.Request, algorithm registry.Algorithm) (pipeline.Request, bool) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
 
-		// now set the algorithm file name and exit the loop
-		algorithmFileName = fmt.Sprintf("%s/%s", globals.Environment["INTERNAL_ALGORITHM_LOCATION"], entry.Name())
-		break
+	// get the municipals and shapes identifying the regions from which the
+	// water usages shall be taken and the consumer groups from the query
+	// parameters. they may be overwritten by the request body
+	shapes, err := parseShapes(r.URL.Query()["shape"])
+	if err != nil {
+		e := ErrInvalidArea
+		e.Error = err.Error()
+		errorHandler <- e
+		<-statusChannel
+		return pipeline.Request{}, false
+	}
+	selection := types.ForecastRequest{
+		Shapes:         shapes,
+		Keys:           r.URL.Query()["key"],
+		ConsumerGroups: r.URL.Query()["consumerGroup"],
+		From:           r.URL.Query().Get("from"),
+		Until:          r.URL.Query().Get("until"),
+		BucketSize:     r.URL.Query().Get("bucketSize"),
 	}
 
-	// now check if the algorithm file name is still empty
-	if strings.TrimSpace(algorithmFileName) == "" {
-		errorHandler <- ErrUnknownAlgorithm
-		<-statusChannel
-		return
+	if r.Method == "POST" {
+		if problem := readForecastBody(w, r, &selection); problem != nil {
+			errorHandler <- problem
+			<-statusChannel
+			return pipeline.Request{}, false
+		}
 	}
 
-	metaFilePath := fmt.Sprintf("%s/%s.yaml", globals.Environment["INTERNAL_ALGORITHM_LOCATION"], algorithmName)
-	var metadata types.AlgorithmMetadata
-	file, err := os.Open(metaFilePath)
-	if err != nil {
-		errorHandler <- fmt.Errorf("unable to open script metadata: %w", err)
-		<-statusChannel
-		return
+	if string(selection.Geometry) == "null" {
+		selection.Geometry = nil
 	}
-	err = yaml.NewDecoder(file).Decode(&metadata)
-	if err != nil {
-		errorHandler <- fmt.Errorf("unable to parse script metadata: %w", err)
+	if len(selection.Keys) == 0 && len(selection.Shapes) == 0 && len(selection.Geometry) == 0 {
+		errorHandler <- ErrNoAreaSelected
 		<-statusChannel
-		return
+		return pipeline.Request{}, false
+	}
+	for _, key := range selection.Keys {
+		if !municipalKeyPattern.MatchString(key) {
+			e := ErrInvalidMunicipalKey
+			e.Error = fmt.Sprintf("invalid municipality key '%s'", key)
+			errorHandler <- e
+			<-statusChannel
+			return pipeline.Request{}, false
+		}
 	}
 
-	log.Debug().Msg("pulling usage data from the database")
-	var query string
-	var args []interface{}
-
-	switch {
-	case metadata.UseBuckets && consumerGroupsSet:
-		query, err = globals.SqlQueries.Raw("get-bucketed-usages-by-municipality-consumer-groups")
-		args = []interface{}{metadata.BucketSize, keyRegEx, consumerGroupsSet}
-		break
-	case metadata.UseBuckets && !consumerGroupsSet:
-		query, err = globals.SqlQueries.Raw("get-bucketed-usages-by-municipality")
-		args = []interface{}{metadata.BucketSize, keyRegEx}
-		break
-	case !metadata.UseBuckets && consumerGroupsSet:
-		query, err = globals.SqlQueries.Raw("get-usages-by-municipality-consumer-groups")
-		args = []interface{}{keyRegEx, consumerGroups}
-		break
-	case !metadata.UseBuckets && !consumerGroupsSet:
-		query, err = globals.SqlQueries.Raw("get-usages-by-municipality")
-		args = []interface{}{keyRegEx}
+	if len(selection.Geometry) > 0 {
+		if err := validateGeometry(selection.Geometry); err != nil {
+			e := ErrInvalidArea
+			e.Error = err.Error()
+			errorHandler <- e
+			<-statusChannel
+			return pipeline.Request{}, false
+		}
 	}
 
+	// now parse the time range restricting the usage data
+	from, until, err := parseTimeRange(selection.From, selection.Until)
 	if err != nil {
-		errorHandler <- fmt.Errorf("unable to prepare query for usage data: %w", err)
+		e := ErrInvalidTimeRange
+		e.Error = err.Error()
+		errorHandler <- e
 		<-statusChannel
-		return
+		return pipeline.Request{}, false
 	}
 
-	var usageDataPoints []types.UsageDataPoint
-	err = pgxscan.Select(r.Context(), globals.Db, &usageDataPoints, query, args...)
+	// now determine the size of the buckets used to aggregate the usage data
+	var bucketSize pgtype.Interval
+	if algorithm.Metadata.UseBuckets || selection.BucketSize != "" {
+		bucketSize, err = algorithm.BucketSize(selection.BucketSize)
+		if err != nil {
+			e := ErrInvalidBucketSize
+			e.Error = err.Error()
+			errorHandler <- e
+			<-statusChannel
+			return pipeline.Request{}, false
+		}
+	}
 
+	// now validate the parameters against the metadata of the algorithm and
+	// fill in the default values
+	parameters, err := algorithm.PrepareParameters(selection.Parameters)
 	if err != nil {
-		errorHandler <- err
+		if errors.Is(err, registry.ErrInvalidParameters) {
+			e := ErrInvalidParameters
+			e.Error = err.Error()
+			errorHandler <- e
+		} else {
+			errorHandler <- err
+		}
 		<-statusChannel
-		return
+		return pipeline.Request{}, false
 	}
 
-	log.Debug().Msg("pulled usage data from the database")
-	// now write the usage data points into a temporary json file
-	log.Debug().Msg("writing usage data to file")
-	tempDataFile, err := os.CreateTemp("", "forecast.*.input")
-	defer tempDataFile.Close()
+	return pipeline.Request{
+		Algorithm:      algorithm,
+		MunicipalKeys:  selection.Keys,
+		Shapes:         selection.Shapes,
+		Geometry:       selection.Geometry,
+		ConsumerGroups: selection.ConsumerGroups,
+		Parameters:     parameters,
+		BucketSize:     bucketSize,
+		From:           from,
+		Until:          until,
+	}, true
+}
+
+// parseTimeRange parses the optional boundaries of the time range used to
+// select the usage data. The boundaries may either be RFC 3339 timestamps or
+// dates
+func parseTimeRange(rawFrom, rawUntil string) (from, until *time.Time, err error) {
+	from, err = parseTimestamp(rawFrom)
 	if err != nil {
-		errorHandler <- fmt.Errorf("unable to create temporary data file: %w", err)
-		<-statusChannel
-		return
+		return nil, nil, fmt.Errorf("invalid start of time range: %w", err)
 	}
-	err = json.NewEncoder(tempDataFile).Encode(usageDataPoints)
+	until, err = parseTimestamp(rawUntil)
 	if err != nil {
-		errorHandler <- fmt.Errorf("unable to write usage data to file: %w", err)
-		<-statusChannel
-		return
+		return nil, nil, fmt.Errorf("invalid end of time range: %w", err)
 	}
-	log.Debug().Msg("wrote data to temporary file")
+	if from != nil && until != nil && from.After(*until) {
+		return nil, nil, errors.New("start of time range is after its end")
+	}
+	return from, until, nil
+}
 
-	// now create a temporary file which will contain the result of the
-	// forecasting algorithm
-	outputFileName := strings.ReplaceAll(tempDataFile.Name(), "input", "output")
-	outputFile, err := os.Create(outputFileName)
-	defer outputFile.Close()
+// parseTimestamp parses a single RFC 3339 timestamp or date. Empty values
+// result in nil
+func parseTimestamp(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
 	if err != nil {
-		errorHandler <- fmt.Errorf("unable to open temporaray output file: %w", err)
-		<-statusChannel
-		return
+		timestamp, err = time.Parse(time.DateOnly, value)
 	}
-
-	// now create a temporary file for the parameters
-	parameterFileName := strings.ReplaceAll(tempDataFile.Name(), "input", "parameter")
-	parameterFile, err := os.Create(parameterFileName)
-	defer parameterFile.Close()
-	defer os.Remove(parameterFileName)
 	if err != nil {
-		errorHandler <- fmt.Errorf("unable to create parameter file: %w", err)
-		<-statusChannel
-		return
+		return nil, fmt.Errorf("'%s' is neither a RFC 3339 timestamp nor a date", value)
 	}
+	return &timestamp, nil
+}
 
-	err = r.ParseMultipartForm(5242880)
-	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
-		errorHandler <- fmt.Errorf("unable to parse form body: %w", err)
-		<-statusChannel
-		return
+// readForecastBody reads the selection and the parameters from the body of the
+// request into the selection. JSON bodies contain the complete selection while
+// multipart and url-encoded forms only contain the parameters in the
+// `parameters` or the legacy `parameter` field.
+// If the body is not supported or invalid, the matching error is returned
+func readForecastBody(w http.ResponseWriter, r *http.Request, selection *types.ForecastRequest) interface{} {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		if r.ContentLength > 0 {
+			return ErrUnsupportedContentType
+		}
+		// requests without a body use the default parameters
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ErrUnsupportedContentType
 	}
 
-	if r.Method == "POST" && r.MultipartForm != nil && r.MultipartForm.Value["parameter"] != nil {
-		c, err := parameterFile.Write([]byte(r.MultipartForm.Value["parameter"][0]))
-		log.Debug().Int("bytes", c).Msg("wrote parameter")
-		if err != nil {
-			errorHandler <- fmt.Errorf("write to parameter file: %w", err)
-			<-statusChannel
-			return
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+	switch mediaType {
+	case "application/json":
+		var body types.ForecastRequest
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&body); err != nil {
+			e := ErrInvalidRequestBody
+			e.Error = err.Error()
+			return e
+		}
+		if body.Keys != nil {
+			selection.Keys = body.Keys
+		}
+		if body.Shapes != nil {
+			selection.Shapes = body.Shapes
+		}
+		if body.Geometry != nil {
+			selection.Geometry = body.Geometry
+		}
+		if body.ConsumerGroups != nil {
+			selection.ConsumerGroups = body.ConsumerGroups
+		}
+		if body.From != "" {
+			selection.From = body.From
+		}
+		if body.Until != "" {
+			selection.Until = body.Until
+		}
+		if body.BucketSize != "" {
+			selection.BucketSize = body.BucketSize
+		}
+		selection.Parameters = body.Parameters
+		if string(selection.Parameters) == "null" {
+			selection.Parameters = nil
 		}
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		err := r.ParseMultipartForm(maxBodySize)
+		if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			e := ErrInvalidRequestBody
+			e.Error = err.Error()
+			return e
+		}
+		if value := r.PostFormValue("parameters"); value != "" {
+			selection.Parameters = json.RawMessage(value)
+		} else if value := r.PostFormValue("parameter"); value != "" {
+			selection.Parameters = json.RawMessage(value)
+		}
+	default:
+		return ErrUnsupportedContentType
 	}
+	return nil
+}
 
-	// now call the algorithm
-	log.Debug().Msg("calling algorithm")
-	err = helpers.CallAlgorithm(algorithmFileName, tempDataFile.Name(), outputFile.Name(), parameterFile.Name())
-	if err != nil {
-		errorHandler <- fmt.Errorf("unable to run algorithm: %w", err)
-		<-statusChannel
-		return
-	}
-	log.Debug().Msg("algorithm finished")
+// sendForecastError converts the errors returned while scheduling or running
+// a forecast into the matching responses and sends them to the error handler
+func sendForecastError(w http.ResponseWriter, r *http.Request, err error) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
 
-	// now read the contents from the output file and send them directly back to the client
-	w.Header().Set("Content-Type", "application/json")
-	_, err = io.Copy(w, outputFile)
-	if err != nil {
-		errorHandler <- fmt.Errorf("unable to read results: %w", err)
-		<-statusChannel
-		return
+	var executionError *helpers.ExecutionError
+	switch {
+	case errors.Is(err, scheduler.ErrQueueFull):
+		w.Header().Set("Retry-After", retryAfter)
+		errorHandler <- ErrServiceBusy
+	case errors.Is(err, pipeline.ErrEmptyArea):
+		errorHandler <- ErrEmptyArea
+	case errors.Is(err, pipeline.ErrTimedOut):
+		errorHandler <- ErrForecastTimedOut
+	case errors.Is(err, native.ErrUnsuitableData):
+		e := ErrUnsuitableUsageData
+		e.Error = err.Error()
+		errorHandler <- e
+	case errors.Is(err, helpers.ErrSandbox):
+		e := ErrSandboxUnavailable
+		e.Error = err.Error()
+		errorHandler <- e
+	case errors.Is(err, registry.ErrInvalidOutput):
+		e := ErrInvalidAlgorithmOutput
+		e.Error = err.Error()
+		errorHandler <- e
+	case errors.As(err, &executionError):
+		e := ErrAlgorithmFailed
+		e.Error = executionError.Error()
+		details := types.AlgorithmExecutionFailure{
+			ExitCode:  executionError.ExitCode,
+			Signal:    executionError.Signal,
+			Truncated: executionError.Truncated,
+		}
+		if globals.DebugAlgorithms {
+			details.Excerpt = executionError.Excerpt()
+		}
+		e.Details = details
+		errorHandler <- e
+	case errors.Is(err, context.Canceled):
+		log.Info().Msg("forecast cancelled since client disconnected")
+		errorHandler <- fmt.Errorf("forecast cancelled: %w", err)
+	default:
+		errorHandler <- err
 	}
+	<-statusChannel
 }
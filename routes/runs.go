@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	wisdomMiddlware "github.com/wisdom-oss/microservice-middlewares/v4"
+
+	"github.com/wisdom-oss/service-usage-forecasts/globals"
+	"github.com/wisdom-oss/service-usage-forecasts/problems"
+	"github.com/wisdom-oss/service-usage-forecasts/types"
+)
+
+// defaultRunLimit contains the number of runs returned if the request does not
+// specify a limit
+const defaultRunLimit = 50
+
+// maxRunLimit contains the maximal number of runs returned in a single request
+const maxRunLimit = 500
+
+// ErrUnknownRun is an error that occurs when the run requested does not exist.
+var ErrUnknownRun = problems.New("UNKNOWN_RUN")
+
+// ErrInvalidLimit is an error that occurs when the number of requested runs
+// is not a positive number or exceeds the maximal number of runs.
+var ErrInvalidLimit = problems.New("INVALID_LIMIT")
+
+// ListRuns returns the recorded runs without their outputs, starting with the
+// most recent run. The runs may be filtered by the algorithm used
+func ListRuns(w http.ResponseWriter, r *http.Request) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	limit := defaultRunLimit
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 || limit > maxRunLimit {
+			errorHandler <- ErrInvalidLimit
+			<-statusChannel
+			return
+		}
+	}
+
+	var algorithm *string
+	if r.URL.Query().Has("algorithm") {
+		identifier := r.URL.Query().Get("algorithm")
+		algorithm = &identifier
+	}
+
+	query, err := globals.SqlQueries.Raw("get-runs")
+	if err != nil {
+		errorHandler <- err
+		<-statusChannel
+		return
+	}
+	runs := make([]types.ForecastRun, 0)
+	err = pgxscan.Select(r.Context(), globals.Db, &runs, query, algorithm, limit)
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable to query runs from database: %w", err)
+		<-statusChannel
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(runs)
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable encode response: %w", err)
+		<-statusChannel
+		return
+	}
+}
+
+// GetRun returns a single recorded run including the output of the algorithm
+func GetRun(w http.ResponseWriter, r *http.Request) {
+	// access the error handlers
+	errorHandler := r.Context().Value(wisdomMiddlware.ErrorChannelName).(chan<- interface{})
+	statusChannel := r.Context().Value(wisdomMiddlware.StatusChannelName).(<-chan bool)
+
+	runID, err := uuid.Parse(chi.URLParam(r, "run-id"))
+	if err != nil {
+		errorHandler <- ErrUnknownRun
+		<-statusChannel
+		return
+	}
+
+	query, err := globals.SqlQueries.Raw("get-run-by-id")
+	if err != nil {
+		errorHandler <- err
+		<-statusChannel
+		return
+	}
+	var run types.ForecastRun
+	err = pgxscan.Get(r.Context(), globals.Db, &run, query, runID.String())
+	if errors.Is(err, pgx.ErrNoRows) {
+		errorHandler <- ErrUnknownRun
+		<-statusChannel
+		return
+	}
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable to query run from database: %w", err)
+		<-statusChannel
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(run)
+	if err != nil {
+		errorHandler <- fmt.Errorf("unable encode response: %w", err)
+		<-statusChannel
+		return
+	}
+}
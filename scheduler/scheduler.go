@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrQueueFull is returned if an execution has been requested while the queue
+// of waiting executions is already full
+var ErrQueueFull = errors.New("execution queue is full")
+
+// Scheduler limits the number of algorithm executions running at the same
+// time. Executions exceeding the limits wait in a bounded queue until a slot
+// is available
+type Scheduler struct {
+	// slots contains a token for every running execution
+	slots chan struct{}
+
+	// queue contains a token for every execution waiting for a slot
+	queue chan struct{}
+
+	// algorithmLock protects the algorithm slots
+	algorithmLock sync.Mutex
+
+	// algorithmSlots contains the slots limiting the executions of a single
+	// algorithm indexed by the identifier of the algorithm
+	algorithmSlots map[string]chan struct{}
+}
+
+// Ticket represents a place in the queue of the scheduler. A ticket needs to
+// be either waited on or discarded to free its place in the queue
+type Ticket struct {
+	scheduler      *Scheduler
+	identifier     string
+	algorithmSlots chan struct{}
+	queuedAt       time.Time
+	leaveQueue     sync.Once
+}
+
+// New creates a new scheduler allowing the supplied number of parallel
+// executions and waiting executions
+func New(parallelExecutions, queueSize int) *Scheduler {
+	return &Scheduler{
+		slots:          make(chan struct{}, parallelExecutions),
+		queue:          make(chan struct{}, queueSize),
+		algorithmSlots: make(map[string]chan struct{}),
+	}
+}
+
+// Enqueue places an execution of the algorithm into the queue without waiting
+// for a free slot.
+// The limit restricts the parallel executions of the algorithm itself and is
+// ignored if it is not positive.
+// If the queue is full, ErrQueueFull is returned.
+func (s *Scheduler) Enqueue(identifier string, limit int) (*Ticket, error) {
+	select {
+	case s.queue <- struct{}{}:
+	default:
+		log.Warn().Str("algorithm", identifier).Msg("rejected execution since queue is full")
+		return nil, ErrQueueFull
+	}
+	log.Debug().Str("algorithm", identifier).Int("position", len(s.queue)).Msg("queued execution")
+	return &Ticket{
+		scheduler:      s,
+		identifier:     identifier,
+		algorithmSlots: s.slotsFor(identifier, limit),
+		queuedAt:       time.Now(),
+	}, nil
+}
+
+// Wait blocks until the execution may start and returns a function which needs
+// to be called once the execution finished.
+// If the context is cancelled while waiting, the error of the context is
+// returned and the ticket is discarded.
+func (t *Ticket) Wait(ctx context.Context) (release func(), err error) {
+	defer t.Discard()
+
+	if t.algorithmSlots != nil {
+		select {
+		case t.algorithmSlots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	select {
+	case t.scheduler.slots <- struct{}{}:
+	case <-ctx.Done():
+		if t.algorithmSlots != nil {
+			<-t.algorithmSlots
+		}
+		return nil, ctx.Err()
+	}
+
+	log.Debug().Str("algorithm", t.identifier).Dur("wait", time.Since(t.queuedAt)).Msg("starting execution")
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-t.scheduler.slots
+			if t.algorithmSlots != nil {
+				<-t.algorithmSlots
+			}
+		})
+	}, nil
+}
+
+// Discard removes the ticket from the queue. Discarding a ticket multiple
+// times or after waiting on it has no effect
+func (t *Ticket) Discard() {
+	t.leaveQueue.Do(func() {
+		<-t.scheduler.queue
+	})
+}
+
+// slotsFor returns the slots used to limit the parallel executions of the
+// algorithm. If the limit changed since the last call, new slots are created.
+// Executions holding a slot of the previous limit release it into the old
+// slots.
+func (s *Scheduler) slotsFor(identifier string, limit int) chan struct{} {
+	if limit <= 0 {
+		return nil
+	}
+	s.algorithmLock.Lock()
+	defer s.algorithmLock.Unlock()
+	slots, exists := s.algorithmSlots[identifier]
+	if !exists || cap(slots) != limit {
+		slots = make(chan struct{}, limit)
+		s.algorithmSlots[identifier] = slots
+	}
+	return slots
+}
@@ -0,0 +1,21 @@
+package types
+
+// AlgorithmExecutionFailure describes how the execution of an algorithm
+// failed. It is sent to the client together with the error
+type AlgorithmExecutionFailure struct {
+	// ExitCode contains the exit code of the algorithm. It is -1 if the
+	// algorithm has been terminated by a signal
+	ExitCode int `json:"exitCode"`
+
+	// Signal contains the name of the signal which terminated the algorithm
+	Signal string `json:"signal,omitempty"`
+
+	// Excerpt contains the end of the error output of the algorithm with the
+	// directories removed from paths. It is only set if debugging of
+	// algorithms is enabled
+	Excerpt string `json:"excerpt,omitempty"`
+
+	// Truncated indicates that the beginning of the output of the algorithm
+	// has been discarded while capturing it
+	Truncated bool `json:"truncated,omitempty"`
+}
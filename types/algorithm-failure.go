@@ -0,0 +1,9 @@
+package types
+
+type AlgorithmFailure struct {
+	// File contains the path of the script that could not be loaded
+	File string `json:"file"`
+
+	// Error contains the reason why the script could not be loaded
+	Error string `json:"error"`
+}
@@ -14,11 +14,23 @@ type AlgorithmInformation struct {
 	//  requests, and other purposes
 	Identifier string `json:"identifier"`
 
-	// BucketConfiguration
+	// Origin contains the origin of the algorithm, which is either `internal`
+	// for algorithms shipped with the service or `external` for algorithms
+	// which have been added to the service
+	Origin string `json:"origin"`
+
+	// Native indicates that the algorithm is implemented directly in the
+	// service instead of being executed as script
+	Native bool `json:"native"`
+
+	// BucketConfiguration describes how the usage data is aggregated before
+	// it is passed to the algorithm and if the size of the buckets may be
+	// changed by the request
 	BucketConfiguration struct {
-		UseBuckets bool   `json:"useBuckets"`
-		BucketSize string `json:"bucketSize,omitempty"`
-	} `json:"-"`
+		UseBuckets    bool   `json:"useBuckets"`
+		BucketSize    string `json:"bucketSize,omitempty"`
+		AllowOverride bool   `json:"allowOverride"`
+	} `json:"bucketConfiguration"`
 
 	// Parameter describes the parameters and is directly read from the
 	// file containing the metadata
@@ -19,4 +19,22 @@ type AlgorithmMetadata struct {
 
 	// BucketSize specifies the size of each bucket as a postgres interval
 	BucketSize string `json:"bucketSize" yaml:"bucketSize"`
+
+	// AllowBucketOverride specifies if the size of the buckets may be changed
+	// by the request
+	AllowBucketOverride bool `json:"allowBucketOverride,omitempty" yaml:"allowBucketOverride"`
+
+	// Timeout specifies the maximal duration of a single execution of the
+	// algorithm as a go duration (e.g., `90s` or `5m`). If no timeout is set,
+	// the default timeout configured for the service is used
+	Timeout string `json:"timeout,omitempty" yaml:"timeout"`
+
+	// MaxParallelExecutions limits the number of executions of the algorithm
+	// running at the same time in addition to the limit configured for the
+	// service. If the value is not set, only the service-wide limit applies
+	MaxParallelExecutions int `json:"maxParallelExecutions,omitempty" yaml:"maxParallelExecutions"`
+
+	// Sandbox configures the restrictions applied to the algorithm while it is
+	// executed
+	Sandbox SandboxConfiguration `json:"sandbox,omitempty" yaml:"sandbox"`
 }
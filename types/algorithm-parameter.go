@@ -19,4 +19,10 @@ type Parameter struct {
 	// Max contains a numerical limit or maximum number of the field
 	Max *int `yaml:"max" json:"max,omitempty"`
 	Min *int `yaml:"min" json:"min,omitempty"`
+
+	// ExclusiveMax and ExclusiveMin exclude the limits themselves from the
+	// allowed values, e.g., to limit a float to the open interval between
+	// two integers
+	ExclusiveMax bool `yaml:"exclusiveMax" json:"exclusiveMax,omitempty"`
+	ExclusiveMin bool `yaml:"exclusiveMin" json:"exclusiveMin,omitempty"`
 }
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobStatus describes the state of an asynchronous forecast
+type JobStatus string
+
+const (
+	// JobQueued is used for jobs waiting for their execution
+	JobQueued JobStatus = "queued"
+
+	// JobRunning is used for jobs whose algorithm is currently executed
+	JobRunning JobStatus = "running"
+
+	// JobSucceeded is used for jobs which finished and contain a result
+	JobSucceeded JobStatus = "succeeded"
+
+	// JobFailed is used for jobs which finished with an error
+	JobFailed JobStatus = "failed"
+
+	// JobCancelled is used for jobs which have been cancelled by a user
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Finished checks if the status indicates that the job will not change anymore
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+type ForecastJob struct {
+	// ID contains the identifier of the job used to query its status
+	ID string `json:"id"`
+
+	// Algorithm contains the identifier of the algorithm used for the forecast
+	Algorithm string `json:"algorithm"`
+
+	// Status contains the current state of the job
+	Status JobStatus `json:"status"`
+
+	// CreatedAt contains the time at which the job has been submitted
+	CreatedAt time.Time `json:"createdAt"`
+
+	// StartedAt contains the time at which the algorithm has been started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+
+	// FinishedAt contains the time at which the job finished
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
+	// Error contains the reason why the job failed
+	Error string `json:"error,omitempty"`
+
+	// Result contains the output of the algorithm once the job succeeded
+	Result json.RawMessage `json:"result,omitempty"`
+}
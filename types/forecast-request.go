@@ -0,0 +1,37 @@
+package types
+
+import "encoding/json"
+
+type ForecastRequest struct {
+	// Keys contains the keys of the municipals from which the water usages are
+	// used. Each key is used as a prefix for the selected area
+	Keys []string `json:"keys"`
+
+	// Shapes contains the ids of the shapes from the geodata tables selecting
+	// the municipalities whose water usages are used
+	Shapes []int64 `json:"shapes"`
+
+	// Geometry contains a GeoJSON polygon or multipolygon selecting the
+	// municipalities whose water usages are used
+	Geometry json.RawMessage `json:"geometry"`
+
+	// ConsumerGroups contains the external identifiers of the consumer groups
+	// whose water usages are used
+	ConsumerGroups []string `json:"consumerGroups"`
+
+	// From restricts the usage data to usages recorded at or after the
+	// timestamp (RFC 3339 or YYYY-MM-DD)
+	From string `json:"from"`
+
+	// Until restricts the usage data to usages recorded at or before the
+	// timestamp (RFC 3339 or YYYY-MM-DD)
+	Until string `json:"until"`
+
+	// BucketSize overrides the size of the buckets used to aggregate the usage
+	// data (e.g., `1 month` or `1 quarter`) if the algorithm allows it
+	BucketSize string `json:"bucketSize"`
+
+	// Parameters contains the parameters passed to the algorithm as key-value
+	// pairs
+	Parameters json.RawMessage `json:"parameters"`
+}
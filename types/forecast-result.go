@@ -0,0 +1,58 @@
+package types
+
+import "time"
+
+// ForecastResult contains the output of an algorithm. It covers the
+// `NumPyResult` and `ProphetResult` schemas from the API documentation, which
+// only differ in the curves contained in the metadata and the uncertainty of
+// the data points
+type ForecastResult struct {
+	// Meta contains information about the curves fitted to the usage data
+	Meta ForecastMetadata `json:"meta"`
+
+	// Data contains the aggregated usage data and the forecasted values
+	Data []ForecastDataPoint `json:"data"`
+}
+
+// ForecastMetadata contains the information about the models used to calculate
+// the forecast
+type ForecastMetadata struct {
+	// Curves contains the equations of the fitted curves mapped to the label
+	// of the data series
+	Curves map[string]string `json:"curves,omitempty"`
+
+	// RScores contains the coefficient of determination of the fitted curves
+	// mapped to the label of the data series
+	RScores map[string]float64 `json:"rScores"`
+
+	// RealDataUntil contains the last x-value containing real usage data
+	// mapped to the label of the data series
+	RealDataUntil map[string]float64 `json:"realDataUntil"`
+
+	// Models contains a description of the model fitted to the data series
+	// (e.g., the selected orders or smoothing parameters) mapped to the label
+	// of the data series
+	Models map[string]string `json:"models,omitempty"`
+}
+
+// ForecastDataPoint contains a single value of a data series. It is either
+// taken from the usage data or forecasted
+type ForecastDataPoint struct {
+	// Label identifies the data series the data point belongs to
+	Label string `json:"label"`
+
+	// X contains the year of the data point. For data points covering a
+	// month, the fraction of the year elapsed before the month is added
+	X float64 `json:"x"`
+
+	// Y contains the amount of water used in the period of the data point
+	Y float64 `json:"y"`
+
+	// Date contains the start of the month covered by the data point. It is
+	// only set for monthly data points
+	Date *time.Time `json:"date,omitempty"`
+
+	// Uncertainty contains the lower and upper bound of the prediction
+	// interval of forecasted values
+	Uncertainty *[2]float64 `json:"uncertainty,omitempty"`
+}
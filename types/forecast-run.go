@@ -0,0 +1,73 @@
+package types
+
+import (
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RunStatus describes how the execution of an algorithm ended
+type RunStatus string
+
+const (
+	// RunSucceeded is used for runs that produced an output
+	RunSucceeded RunStatus = "succeeded"
+
+	// RunFailed is used for runs in which the algorithm returned an error
+	RunFailed RunStatus = "failed"
+
+	// RunTimedOut is used for runs that have been stopped after exceeding the
+	// timeout of the algorithm
+	RunTimedOut RunStatus = "timed-out"
+
+	// RunCancelled is used for runs that have been cancelled by the client
+	RunCancelled RunStatus = "cancelled"
+)
+
+type ForecastRun struct {
+	// ID contains the identifier of the run
+	ID pgtype.UUID `json:"id" db:"id"`
+
+	// Algorithm contains the identifier of the algorithm used for the run
+	Algorithm string `json:"algorithm" db:"algorithm"`
+
+	// Parameters contains the parameters passed to the algorithm
+	Parameters json.RawMessage `json:"parameters,omitempty" db:"parameters"`
+
+	// MunicipalityKeys contains the keys used to select the area
+	MunicipalityKeys []string `json:"municipalityKeys" db:"municipality_keys"`
+
+	// ConsumerGroups contains the external identifiers of the consumer groups
+	// used to filter the water usages
+	ConsumerGroups []string `json:"consumerGroups" db:"consumer_groups"`
+
+	// DataFrom contains the timestamp of the oldest water usage passed to the
+	// algorithm
+	DataFrom pgtype.Timestamptz `json:"dataFrom" db:"data_from"`
+
+	// DataUntil contains the timestamp of the newest water usage passed to the
+	// algorithm
+	DataUntil pgtype.Timestamptz `json:"dataUntil" db:"data_until"`
+
+	// Output contains the output written by the algorithm
+	Output json.RawMessage `json:"output,omitempty" db:"output"`
+
+	// Duration contains the execution time of the algorithm in seconds
+	Duration float64 `json:"duration" db:"duration"`
+
+	// Status contains the way the execution ended
+	Status RunStatus `json:"status" db:"status"`
+
+	// ExitCode contains the exit code of the algorithm if it has been
+	// available
+	ExitCode *int `json:"exitCode,omitempty" db:"exit_code"`
+
+	// Signal contains the name of the signal which terminated the algorithm
+	Signal *string `json:"signal,omitempty" db:"signal"`
+
+	// Error contains the error returned by the execution
+	Error *string `json:"error,omitempty" db:"error"`
+
+	// CreatedAt contains the time at which the run has been recorded
+	CreatedAt pgtype.Timestamptz `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,33 @@
+package types
+
+// SandboxConfiguration describes the restrictions applied to an algorithm
+// while it is executed. Unset values use the defaults of the service. The
+// sandbox is only available on Linux.
+// Algorithms which are not shipped with the service may only lower the limits
+// below the defaults
+type SandboxConfiguration struct {
+	// Disabled executes the algorithm without any restrictions. Only algorithms
+	// shipped with the service may disable the sandbox
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled"`
+
+	// CPUTime limits the processor time used by the algorithm as a go duration
+	// (e.g., `90s` or `5m`)
+	CPUTime string `json:"cpuTime,omitempty" yaml:"cpuTime"`
+
+	// Memory limits the address space of the algorithm in mebibytes
+	Memory int `json:"memory,omitempty" yaml:"memory"`
+
+	// OpenFiles limits the number of files the algorithm may open at the same
+	// time
+	OpenFiles int `json:"openFiles,omitempty" yaml:"openFiles"`
+
+	// Network allows the algorithm to access the network
+	Network bool `json:"network,omitempty" yaml:"network"`
+
+	// User contains the id of the user executing the algorithm. The root user
+	// can not be used
+	User int `json:"user,omitempty" yaml:"user"`
+
+	// Group contains the id of the group executing the algorithm
+	Group int `json:"group,omitempty" yaml:"group"`
+}